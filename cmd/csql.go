@@ -8,6 +8,7 @@ import (
 	"github.com/rivo/tview"
 
 	"github.com/rhariady/csql/pkg/app"
+	"github.com/rhariady/csql/pkg/audit"
 	"github.com/rhariady/csql/pkg/config"
 	_ "github.com/rhariady/csql/pkg/dbadapter"
 	"github.com/rhariady/csql/pkg/session"
@@ -24,6 +25,11 @@ func main() {
 		panic(err)
 	}
 
+	err = audit.Configure(cfg.Audit)
+	if err != nil {
+		panic(err)
+	}
+
 	if !isatty.IsTerminal(os.Stdout.Fd()) {
 		panic("This application is intended to be run in an interactive terminal.")
 	} else {
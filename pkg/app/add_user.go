@@ -26,8 +26,8 @@ func (a *AddUser) GetContent(s *session.Session) tview.Primitive {
 
 	for authType, authConfig := range auth.AuthList {
 		auth_type.AddOption(authType, func() {
-			for form.GetFormItemCount() > 3 {
-				form.RemoveFormItem(3)
+			for form.GetFormItemCount() > 4 {
+				form.RemoveFormItem(4)
 			}
 			authConfig.GetFormInput(form)
 		})
@@ -39,11 +39,13 @@ func (a *AddUser) GetContent(s *session.Session) tview.Primitive {
 	form = tview.NewForm().
 		AddInputField("Username", "", 0, nil, nil).
 		AddInputField("Default database", "", 0, nil, nil).
+		AddCheckbox("Read-only", false, nil).
 		AddFormItem(auth_type).
 		AddButton("Add User", func() {
 			username := form.GetFormItemByLabel("Username").(*tview.InputField).GetText()
 			default_database := form.GetFormItemByLabel("Default database").(*tview.InputField).GetText()
-			_, authType := form.GetFormItem(2).(*tview.DropDown).GetCurrentOption()
+			read_only := form.GetFormItemByLabel("Read-only").(*tview.Checkbox).IsChecked()
+			_, authType := form.GetFormItem(3).(*tview.DropDown).GetCurrentOption()
 
 			authAdapter, err := auth.GetAuth(authType, nil)
 			if err != nil {
@@ -56,6 +58,7 @@ func (a *AddUser) GetContent(s *session.Session) tview.Primitive {
 				DefaultDatabase: default_database,
 				AuthType:        authType,
 				AuthParams:      authParams,
+				ReadOnly:        read_only,
 			}
 
 			a.instance = s.Config.AddInstanceUser(a.instance.Name, newUser)
@@ -0,0 +1,92 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/rhariady/csql/pkg/config"
+	"github.com/rhariady/csql/pkg/session"
+)
+
+type EditInstance struct {
+	instance_list *InstanceList
+	instance      *config.InstanceConfig
+}
+
+func (e *EditInstance) GetTitle() string {
+	return fmt.Sprintf("Edit Instance - %s", e.instance.Name)
+}
+
+func (e *EditInstance) GetContent(s *session.Session) tview.Primitive {
+	var form *tview.Form
+
+	environment := tview.NewDropDown().
+		SetLabel("Environment").
+		SetOptions(append([]string{""}, config.Environments...), nil)
+	environment.SetListStyles(tcell.StyleDefault.Background(tcell.ColorGray), tcell.StyleDefault.Background(tcell.ColorWhite).Foreground(tcell.ColorGreen)).
+		SetFocusedStyle(tcell.StyleDefault.Background(tcell.ColorWhite).Foreground(tcell.ColorGreen)).
+		SetPrefixStyle(tcell.StyleDefault.Background(tcell.ColorWhite).Foreground(tcell.ColorGreen))
+	environment.SetCurrentOption(slices.Index(config.Environments, e.instance.Environment) + 1)
+
+	form = tview.NewForm().
+		AddFormItem(environment).
+		AddInputField("Statement timeout", e.instance.StatementTimeout, 0, nil, nil).
+		AddInputField("Group", e.instance.Group, 0, nil, nil).
+		AddInputField("Tags", strings.Join(e.instance.Tags, ", "), 0, nil, nil).
+		AddButton("Save", func() {
+			group := form.GetFormItemByLabel("Group").(*tview.InputField).GetText()
+			tags := form.GetFormItemByLabel("Tags").(*tview.InputField).GetText()
+			_, e.instance.Environment = environment.GetCurrentOption()
+			e.instance.StatementTimeout = strings.TrimSpace(form.GetFormItemByLabel("Statement timeout").(*tview.InputField).GetText())
+
+			e.instance.Group = strings.Trim(strings.TrimSpace(group), "/")
+			e.instance.Tags = nil
+			for _, tag := range strings.Split(tags, ",") {
+				tag = strings.TrimSpace(tag)
+				if tag != "" {
+					e.instance.Tags = append(e.instance.Tags, tag)
+				}
+			}
+
+			s.Config.AddInstance(*e.instance)
+			err := s.Config.WriteConfig()
+			if err != nil {
+				s.ShowMessage(fmt.Sprintf("Error Writing Config:\n%s", err), true)
+			}
+
+			s.CloseModal()
+			e.instance_list.RefreshInstanceTable(s)
+		}).
+		AddButton("Cancel", func() {
+			s.CloseModal()
+		})
+
+	form.SetFieldBackgroundColor(tcell.ColorGray)
+	form.SetLabelColor(tcell.ColorRed)
+	form.SetButtonBackgroundColor(tcell.ColorDarkGray)
+
+	return form
+}
+
+func (e *EditInstance) GetKeyBindings() (keybindings []*session.KeyBinding) {
+	return
+}
+
+func (e *EditInstance) GetInfo() (info []session.Info) {
+	return
+}
+
+func (e *EditInstance) ExecuteCommand(s *session.Session, command string) error {
+	return nil
+}
+
+func NewEditInstance(instance_list *InstanceList, instance *config.InstanceConfig) *EditInstance {
+	return &EditInstance{
+		instance_list: instance_list,
+		instance:      instance,
+	}
+}
@@ -9,12 +9,114 @@ import (
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 
+	"github.com/rhariady/csql/pkg/config"
 	"github.com/rhariady/csql/pkg/discovery"
 	"github.com/rhariady/csql/pkg/session"
 )
 
 type InstanceList struct {
 	instanceTable *tview.Table
+	filterView    *session.FilterView
+	collapsed     map[string]bool
+	filter        InstanceFilter
+}
+
+// InstanceFilter narrows down the instances shown in the instance list. Empty
+// fields match every instance.
+type InstanceFilter struct {
+	Group       string
+	Tag         string
+	Type        string
+	Source      string
+	Environment string
+}
+
+func (f InstanceFilter) IsEmpty() bool {
+	return f == InstanceFilter{}
+}
+
+func (f InstanceFilter) Match(instance config.InstanceConfig) bool {
+	if f.Group != "" {
+		group := strings.Join(instance.GetGroupPath(), "/")
+		if group != f.Group && !strings.HasPrefix(group, f.Group+"/") {
+			return false
+		}
+	}
+	if f.Tag != "" && !slices.Contains(instance.GetTags(), f.Tag) {
+		return false
+	}
+	if f.Type != "" && !strings.EqualFold(instance.Type, f.Type) {
+		return false
+	}
+	if f.Source != "" && !strings.EqualFold(instance.Source, f.Source) {
+		return false
+	}
+	if f.Environment != "" && !strings.EqualFold(instance.Environment, f.Environment) {
+		return false
+	}
+	return true
+}
+
+func (f InstanceFilter) String() string {
+	var filters []string
+	if f.Group != "" {
+		filters = append(filters, fmt.Sprintf("group=%s", f.Group))
+	}
+	if f.Tag != "" {
+		filters = append(filters, fmt.Sprintf("tag=%s", f.Tag))
+	}
+	if f.Type != "" {
+		filters = append(filters, fmt.Sprintf("type=%s", f.Type))
+	}
+	if f.Source != "" {
+		filters = append(filters, fmt.Sprintf("source=%s", f.Source))
+	}
+	if f.Environment != "" {
+		filters = append(filters, fmt.Sprintf("env=%s", f.Environment))
+	}
+	return strings.Join(filters, " ")
+}
+
+// instanceFolder is a node of the instance tree, built from the instance groups.
+type instanceFolder struct {
+	name      string
+	path      string
+	folders   map[string]*instanceFolder
+	instances []string
+}
+
+func newInstanceFolder(name string, path string) *instanceFolder {
+	return &instanceFolder{
+		name:    name,
+		path:    path,
+		folders: make(map[string]*instanceFolder),
+	}
+}
+
+func (f *instanceFolder) add(groupPath []string, instanceName string) {
+	if len(groupPath) == 0 {
+		f.instances = append(f.instances, instanceName)
+		return
+	}
+
+	folder, ok := f.folders[groupPath[0]]
+	if !ok {
+		path := groupPath[0]
+		if f.path != "" {
+			path = f.path + "/" + path
+		}
+		folder = newInstanceFolder(groupPath[0], path)
+		f.folders[groupPath[0]] = folder
+	}
+	folder.add(groupPath[1:], instanceName)
+}
+
+func (f *instanceFolder) count() int {
+	count := len(f.instances)
+	for _, folder := range f.folders {
+		count += folder.count()
+	}
+	return count
 }
 
 func (i *InstanceList) GetTitle() string {
@@ -28,6 +130,10 @@ func (i *InstanceList) GetContent(s *session.Session) tview.Primitive {
 		SetSelectable(true, false).
 		SetFixed(1, 1)
 
+	i.filterView = session.NewFilterView(i.instanceTable, func(pattern string) {
+		i.RefreshInstanceTable(s)
+	})
+
 	i.RefreshInstanceTable(s)
 
 	// 	Set the selected function for the table (triggered by Enter key)
@@ -35,10 +141,15 @@ func (i *InstanceList) GetContent(s *session.Session) tview.Primitive {
 		if row == 0 { // Skip header row
 			return
 		}
-		instanceName := i.instanceTable.GetCell(row, 0).Text
-		instance := s.Config.GetInstance(instanceName)
-		userList := NewUserList(instance)
-		s.ShowModal(userList)
+		switch reference := i.instanceTable.GetCell(row, 0).GetReference().(type) {
+		case *instanceFolder:
+			i.collapsed[reference.path] = !i.collapsed[reference.path]
+			i.RefreshInstanceTable(s)
+		case string:
+			instance := s.Config.GetInstance(reference)
+			userList := NewUserList(instance)
+			s.ShowModal(userList)
+		}
 	})
 
 	// Set input capture for 'a' key to trigger the same selection logic
@@ -49,9 +160,24 @@ func (i *InstanceList) GetContent(s *session.Session) tview.Primitive {
 			s.ShowModal(discoverDatabase)
 			return nil // Consume the event
 		}
+		if event.Rune() == 'f' {
+			i.filterView.Show(s)
+			return nil
+		}
+		if event.Rune() == 'e' {
+			instanceName, ok := i.getSelectedInstance()
+			if !ok {
+				return nil
+			}
+			editInstance := NewEditInstance(i, s.Config.GetInstance(instanceName))
+			s.ShowModal(editInstance)
+			return nil
+		}
 		if event.Rune() == 'd' {
-			row, column := i.instanceTable.GetSelection()
-			instanceName := i.instanceTable.GetCell(row, column).Text
+			instanceName, ok := i.getSelectedInstance()
+			if !ok {
+				return nil
+			}
 
 			messages := fmt.Sprintf(`Are you sure you want to remove this instance:
 
@@ -62,7 +188,7 @@ func (i *InstanceList) GetContent(s *session.Session) tview.Primitive {
 				if err != nil {
 					s.ShowMessage(fmt.Sprintf("Error: \n%s", err), true)
 				} else {
-					i.instanceTable.RemoveRow(row)
+					i.RefreshInstanceTable(s)
 					s.ShowMessage(fmt.Sprintf("Instance %s has been removed", instanceName), true)
 				}
 			}, func(s *session.Session) {})
@@ -71,8 +197,14 @@ func (i *InstanceList) GetContent(s *session.Session) tview.Primitive {
 		return event
 	})
 
-	return i.instanceTable
+	return i.filterView
+
+}
 
+func (i *InstanceList) getSelectedInstance() (string, bool) {
+	row, _ := i.instanceTable.GetSelection()
+	instanceName, ok := i.instanceTable.GetCell(row, 0).GetReference().(string)
+	return instanceName, ok
 }
 
 func AddInstanceForm() {
@@ -82,56 +214,132 @@ func AddInstanceForm() {
 func (i *InstanceList) GetKeyBindings() (keybindings []*session.KeyBinding) {
 	keybindings = []*session.KeyBinding{
 		session.NewKeyBinding("[a]", "Add new instance(s)"),
+		session.NewKeyBinding("[e]", "Edit instance"),
+		session.NewKeyBinding("[f]", "Filter instances"),
 		session.NewKeyBinding("[d]", "Remove instance"),
-		session.NewKeyBinding("<enter>", "Connect to instance"),
+		session.NewKeyBinding("<enter>", "Connect / toggle group"),
 	}
 	return
 }
 
 func (i *InstanceList) GetInfo() (info []session.Info) {
+	if !i.filter.IsEmpty() {
+		info = append(info, session.NewInfo("Filter", i.filter.String()))
+	}
 	return
 }
 
+// ExecuteCommand handles the instance filters typed in the command bar:
+// "group <path>", "tag <tag>", "type <type>", "source <source>", "env <env>"
+// and "clear".
 func (i *InstanceList) ExecuteCommand(s *session.Session, command string) error {
+	name, value, _ := strings.Cut(strings.TrimSpace(command), " ")
+	value = strings.TrimSpace(value)
+
+	switch name {
+	case "group":
+		i.filter.Group = strings.Trim(value, "/")
+	case "tag":
+		i.filter.Tag = value
+	case "type":
+		i.filter.Type = value
+	case "source":
+		i.filter.Source = value
+	case "env":
+		i.filter.Environment = value
+	case "clear":
+		i.filter = InstanceFilter{}
+	case "":
+		return nil
+	default:
+		return fmt.Errorf("unknown command: %s", name)
+	}
+
+	i.RefreshInstanceTable(s)
+	s.ShowHeader(i.GetInfo(), i.GetKeyBindings())
+	s.App.SetFocus(i.instanceTable)
+
 	return nil
 }
 
 func NewInstanceList() *InstanceList {
-	return &InstanceList{}
+	return &InstanceList{
+		collapsed: make(map[string]bool),
+	}
 }
 
-func (i *InstanceList) RefreshInstanceTable(session *session.Session) {
+func (i *InstanceList) RefreshInstanceTable(s *session.Session) {
 	i.instanceTable.Clear()
 	i.instanceTable.SetCell(0, 0, tview.NewTableCell("Name").SetExpansion(1).SetSelectable(false)).
-		SetCell(0, 1, tview.NewTableCell("Type").SetExpansion(1).SetSelectable(false)).
-		SetCell(0, 2, tview.NewTableCell("Host").SetExpansion(1).SetSelectable(false)).
-		SetCell(0, 3, tview.NewTableCell("Port").SetExpansion(1).SetSelectable(false)).
-		SetCell(0, 4, tview.NewTableCell("Source").SetExpansion(1).SetSelectable(false)).
-		SetCell(0, 5, tview.NewTableCell("Params").SetExpansion(2).SetSelectable(false))
+		SetCell(0, 1, tview.NewTableCell("Env").SetExpansion(1).SetSelectable(false)).
+		SetCell(0, 2, tview.NewTableCell("Type").SetExpansion(1).SetSelectable(false)).
+		SetCell(0, 3, tview.NewTableCell("Host").SetExpansion(1).SetSelectable(false)).
+		SetCell(0, 4, tview.NewTableCell("Port").SetExpansion(1).SetSelectable(false)).
+		SetCell(0, 5, tview.NewTableCell("Source").SetExpansion(1).SetSelectable(false)).
+		SetCell(0, 6, tview.NewTableCell("Tags").SetExpansion(2).SetSelectable(false))
 
-	instancesName := slices.Sorted(maps.Keys(session.Config.Instances))
+	pattern := i.filterView.GetPattern()
 
-	row := 1
-	for _, name := range instancesName {
-		instance := session.Config.Instances[name]
+	root := newInstanceFolder("", "")
+	for name, instance := range s.Config.Instances {
+		if !i.filter.Match(instance) {
+			continue
+		}
+		text := strings.Join(append([]string{name, instance.Group, instance.Environment, instance.Type, instance.Host, fmt.Sprint(instance.Port), instance.Source}, instance.GetTags()...), " ")
+		if !session.FuzzyMatch(pattern, text) {
+			continue
+		}
+		root.add(instance.GetGroupPath(), name)
+	}
+
+	// Keep every group expanded while searching
+	i.addFolderRows(s, root, 0, pattern != "")
+}
+
+func (i *InstanceList) addFolderRows(s *session.Session, folder *instanceFolder, depth int, expandAll bool) {
+	indent := strings.Repeat("  ", depth)
+
+	for _, name := range slices.Sorted(maps.Keys(folder.folders)) {
+		subFolder := folder.folders[name]
+
+		collapsed := i.collapsed[subFolder.path] && !expandAll
+
+		icon := "▾"
+		if collapsed {
+			icon = "▸"
+		}
+
+		row := i.instanceTable.GetRowCount()
+		label := fmt.Sprintf("%s%s %s (%d)", indent, icon, subFolder.name, subFolder.count())
+		i.instanceTable.SetCell(row, 0, tview.NewTableCell(label).SetReference(subFolder).SetTextColor(tcell.ColorYellow))
+
+		if !collapsed {
+			i.addFolderRows(s, subFolder, depth+1, expandAll)
+		}
+	}
+
+	slices.Sort(folder.instances)
+	for _, name := range folder.instances {
+		instance := s.Config.Instances[name]
 		discovery, err := discovery.GetDiscovery(instance.Source)
 		var sourceLabel string
 		if err == nil {
 			sourceLabel = discovery.GetLabel()
 		}
 
-		var param_list []string
-		for param_key, param_value := range instance.Params {
-			param_list = append(param_list, fmt.Sprintf("[%s: %s]", param_key, param_value))
+		var tag_list []string
+		for _, tag := range instance.GetTags() {
+			tag_list = append(tag_list, fmt.Sprintf("[%s]", tag))
 		}
-		params := strings.Join(param_list, " ")
-
-		i.instanceTable.SetCell(row, 0, tview.NewTableCell(name))
-		i.instanceTable.SetCell(row, 1, tview.NewTableCell(instance.Type))
-		i.instanceTable.SetCell(row, 2, tview.NewTableCell(instance.Host))
-		i.instanceTable.SetCell(row, 3, tview.NewTableCell(fmt.Sprint(instance.Port)))
-		i.instanceTable.SetCell(row, 4, tview.NewTableCell(sourceLabel))
-		i.instanceTable.SetCell(row, 5, tview.NewTableCell(params))
-		row++
+		tags := strings.Join(tag_list, " ")
+
+		row := i.instanceTable.GetRowCount()
+		i.instanceTable.SetCell(row, 0, tview.NewTableCell(indent+name).SetReference(name))
+		i.instanceTable.SetCell(row, 1, tview.NewTableCell(instance.Environment).SetTextColor(session.EnvironmentColor(instance.Environment)))
+		i.instanceTable.SetCell(row, 2, tview.NewTableCell(instance.Type))
+		i.instanceTable.SetCell(row, 3, tview.NewTableCell(instance.Host))
+		i.instanceTable.SetCell(row, 4, tview.NewTableCell(fmt.Sprint(instance.Port)))
+		i.instanceTable.SetCell(row, 5, tview.NewTableCell(sourceLabel))
+		i.instanceTable.SetCell(row, 6, tview.NewTableCell(tview.Escape(tags)))
 	}
 }
@@ -19,36 +19,40 @@ func (i *UserList) GetTitle() string {
 	return "Select a user"
 }
 
-func (i *UserList) GetContent(session *session.Session) tview.Primitive {
+func (i *UserList) GetContent(s *session.Session) tview.Primitive {
 	userTable := tview.NewTable().
 		SetBorders(false).
 		SetSelectable(true, false)
 
-	userRow := 0
+	filterTable := session.NewFilterTable(userTable)
+
 	for _, user := range i.instance.Users {
-		userTable.SetCell(userRow, 0, tview.NewTableCell(user.Username))
-		userTable.SetCell(userRow, 1, tview.NewTableCell(fmt.Sprintf("[auth=%s]", user.AuthType)).SetExpansion(1))
-		userRow++
+		attributes := fmt.Sprintf("[auth=%s]", user.AuthType)
+		if user.ReadOnly {
+			attributes += " [read-only]"
+		}
+		filterTable.AddRow(
+			tview.NewTableCell(user.Username),
+			tview.NewTableCell(tview.Escape(attributes)).SetExpansion(1),
+		)
 	}
 
-	userTable.Select(0, 0)
-
 	userTable.SetSelectedFunc(func(row int, column int) {
 		// databaseList := NewDatabaseList(i.instanceName, userName)
-		session.CloseModal()
+		s.CloseModal()
 		userName := userTable.GetCell(row, 0).Text
 		user, err := i.instance.GetUserConfig(userName)
 		if err != nil {
-			session.ShowMessage(fmt.Sprintf("Error:\n%s", err), true)
+			s.ShowMessage(fmt.Sprintf("Error:\n%s", err), true)
 		}
 		dbAdapter, err := dbadapter.GetDBAdapter(i.instance.Type)
 		if err != nil {
-			session.ShowMessage(fmt.Sprintf("Error:\n%s", err), true)
+			s.ShowMessage(fmt.Sprintf("Error:\n%s", err), true)
 			return
 		}
-		err = dbAdapter.Connect(session, i.instance, user, user.DefaultDatabase)
+		err = dbAdapter.Connect(s, i.instance, user, user.DefaultDatabase)
 		if err != nil {
-			session.ShowMessage(fmt.Sprintf("Error:\n%s", err), true)
+			s.ShowMessage(fmt.Sprintf("Error:\n%s", err), true)
 			return
 		}
 
@@ -59,20 +63,24 @@ func (i *UserList) GetContent(session *session.Session) tview.Primitive {
 	userTable.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		switch event.Rune() {
 		case 'a':
-			session.CloseModal()
+			s.CloseModal()
 			add_user := NewAddUser(i.instance)
-			session.ShowModal(add_user)
+			s.ShowModal(add_user)
+			return nil
+		case 'f':
+			filterTable.Show(s)
 			return nil
 		}
 		return event
 	})
 
-	return userTable
+	return filterTable
 }
 
 func (i *UserList) GetKeyBindings() (keybindings []*session.KeyBinding) {
 	keybindings = []*session.KeyBinding{
 		session.NewKeyBinding("(a)", "Add new user"),
+		session.NewKeyBinding("(f)", "Filter users"),
 		session.NewKeyBinding("<enter>", "Select user"),
 	}
 	return
@@ -0,0 +1,135 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rhariady/csql/pkg/config"
+)
+
+// Entry is a statement executed by csql, as recorded in the audit log.
+type Entry struct {
+	Time       time.Time `json:"time"`
+	Instance   string    `json:"instance"`
+	User       string    `json:"user"`
+	Database   string    `json:"database"`
+	Source     string    `json:"source"`
+	Statement  string    `json:"statement"`
+	DurationMs int64     `json:"duration_ms"`
+	// Rows is the number of rows affected or fetched by the statement. Only
+	// the first page of the results streamed from a cursor is fetched when
+	// they are executed.
+	Rows  int64  `json:"rows"`
+	Error string `json:"error,omitempty"`
+}
+
+type Logger struct {
+	mu      sync.Mutex
+	path    string
+	syslog  syslogWriter
+	webhook string
+	client  *http.Client
+}
+
+type syslogWriter interface {
+	Info(message string) error
+}
+
+var logger *Logger
+
+// Configure sets up the audit logger from the configuration. The local log
+// defaults to audit.log in the csql data directory.
+func Configure(auditConfig config.AuditConfig) error {
+	path := auditConfig.Path
+	if path == "" {
+		var err error
+		path, err = config.GetDataFile("audit.log")
+		if err != nil {
+			return err
+		}
+	}
+
+	newLogger := &Logger{
+		path:    path,
+		webhook: auditConfig.Webhook,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+
+	if auditConfig.Syslog {
+		writer, err := newSyslogWriter()
+		if err != nil {
+			return err
+		}
+		newLogger.syslog = writer
+	}
+
+	logger = newLogger
+
+	return nil
+}
+
+// Record appends an entry to the audit log, and forwards it to syslog and to
+// the webhook when they are configured. Forwarding to the webhook is done in
+// the background and its failures are not reported.
+func Record(entry Entry) error {
+	if logger == nil {
+		if err := Configure(config.AuditConfig{}); err != nil {
+			return err
+		}
+	}
+
+	return logger.Record(entry)
+}
+
+func (l *Logger) Record(entry Entry) (err error) {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	file, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		c_err := file.Close()
+		if c_err != nil {
+			err = c_err
+		}
+	}()
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+
+	if l.syslog != nil {
+		if err := l.syslog.Info(string(line)); err != nil {
+			return err
+		}
+	}
+
+	if l.webhook != "" {
+		go l.post(line)
+	}
+
+	return nil
+}
+
+func (l *Logger) post(line []byte) {
+	response, err := l.client.Post(l.webhook, "application/json", bytes.NewReader(line))
+	if err != nil {
+		return
+	}
+	_ = response.Body.Close()
+}
@@ -0,0 +1,11 @@
+//go:build windows || plan9
+
+package audit
+
+import (
+	"fmt"
+)
+
+func newSyslogWriter() (syslogWriter, error) {
+	return nil, fmt.Errorf("syslog is not supported on this platform")
+}
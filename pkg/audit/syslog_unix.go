@@ -0,0 +1,11 @@
+//go:build !windows && !plan9
+
+package audit
+
+import (
+	"log/syslog"
+)
+
+func newSyslogWriter() (syslogWriter, error) {
+	return syslog.New(syslog.LOG_INFO|syslog.LOG_USER, "csql")
+}
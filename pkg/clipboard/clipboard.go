@@ -0,0 +1,72 @@
+package clipboard
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Copy copies a text to the clipboard. The text is sent to the terminal with
+// an OSC 52 escape sequence, which most terminal emulators support, including
+// over SSH. When a local clipboard utility is available (wl-copy, xclip, xsel,
+// pbcopy or clip.exe), the text is also given to it.
+func Copy(text string) error {
+	oscErr := copyOSC52(text)
+
+	command, ok := clipboardCommand()
+	if !ok {
+		return oscErr
+	}
+
+	// The utilities may keep running to serve the clipboard (e.g. xclip), so
+	// they are not waited for
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	if err := cmd.Start(); err != nil {
+		return oscErr
+	}
+	go func() {
+		_ = cmd.Wait()
+	}()
+
+	return nil
+}
+
+func copyOSC52(text string) error {
+	terminal, err := openTerminal()
+	if err != nil {
+		return fmt.Errorf("cannot write to the terminal: %w", err)
+	}
+	defer terminal.Close()
+
+	encoded := base64.StdEncoding.EncodeToString([]byte(text))
+	_, err = fmt.Fprintf(terminal, "\x1b]52;c;%s\x07", encoded)
+	return err
+}
+
+// clipboardCommand returns the command of the clipboard utility of the local
+// desktop, if any.
+func clipboardCommand() ([]string, bool) {
+	var candidates [][]string
+	switch {
+	case runtime.GOOS == "darwin":
+		candidates = [][]string{{"pbcopy"}}
+	case runtime.GOOS == "windows":
+		candidates = [][]string{{"clip.exe"}}
+	case os.Getenv("WAYLAND_DISPLAY") != "":
+		candidates = [][]string{{"wl-copy"}}
+	case os.Getenv("DISPLAY") != "":
+		candidates = [][]string{{"xclip", "-selection", "clipboard"}, {"xsel", "--clipboard", "--input"}}
+	}
+
+	for _, candidate := range candidates {
+		if _, err := exec.LookPath(candidate[0]); err == nil {
+			return candidate, true
+		}
+	}
+
+	return nil, false
+}
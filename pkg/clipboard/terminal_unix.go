@@ -0,0 +1,12 @@
+//go:build !windows
+
+package clipboard
+
+import (
+	"io"
+	"os"
+)
+
+func openTerminal() (io.WriteCloser, error) {
+	return os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+}
@@ -0,0 +1,20 @@
+//go:build windows
+
+package clipboard
+
+import (
+	"io"
+	"os"
+)
+
+type stdoutTerminal struct {
+	io.Writer
+}
+
+func (t stdoutTerminal) Close() error {
+	return nil
+}
+
+func openTerminal() (io.WriteCloser, error) {
+	return stdoutTerminal{os.Stdout}, nil
+}
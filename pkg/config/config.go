@@ -5,12 +5,52 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
 
 	"github.com/BurntSushi/toml"
 )
 
+type Environment = string
+
+const (
+	Development Environment = "dev"
+	Staging     Environment = "staging"
+	Production  Environment = "prod"
+)
+
+var Environments = []Environment{Development, Staging, Production}
+
 type Config struct {
 	Instances map[string]InstanceConfig
+	Audit     AuditConfig    `toml:"audit"`
+	Snippets  SnippetsConfig `toml:"snippets"`
+	Editor    EditorConfig   `toml:"editor"`
+}
+
+// AuditConfig configures the log of the executed statements. The log is
+// always written locally, and optionally forwarded to syslog or a webhook.
+type AuditConfig struct {
+	Path    string `toml:"path"`
+	Syslog  bool   `toml:"syslog"`
+	Webhook string `toml:"webhook"`
+}
+
+// SnippetsConfig lists the files of saved queries. It defaults to
+// snippets.toml in the csql data directory.
+type SnippetsConfig struct {
+	Paths []string `toml:"paths"`
+}
+
+// EditorConfig configures the query editor.
+type EditorConfig struct {
+	// KeywordCase is the case of the keywords written by the SQL formatter:
+	// "upper" (the default), "lower" or "preserve".
+	KeywordCase string `toml:"keyword_case"`
+	// Indent is the number of spaces of an indentation level of the SQL
+	// formatter. It defaults to 2.
+	Indent int `toml:"indent"`
 }
 
 type InstanceConfig struct {
@@ -19,6 +59,15 @@ type InstanceConfig struct {
 	Host   string `toml:"host"`
 	Port   int    `toml:"port"`
 	Type   string `toml:"type"`
+	// Environment enables the guardrails of production instances when set
+	// to "prod".
+	Environment Environment `toml:"environment"`
+	// StatementTimeout aborts the statements running longer than this
+	// duration (e.g. "30s", "5min"). Empty means no timeout.
+	StatementTimeout string `toml:"statement_timeout"`
+	// Group is a "/" separated folder path used to organize the instance list.
+	Group  string   `toml:"group"`
+	Tags   []string `toml:"tags"`
 	Users  []UserConfig
 	Params map[string]interface{} `toml:"params"`
 }
@@ -29,6 +78,8 @@ type UserConfig struct {
 	// Auth AuthConfig `toml:"auth"`
 	AuthType   string                 `toml:"auth_type"`
 	AuthParams map[string]interface{} `toml:"params"`
+	// ReadOnly restricts the user to read-only transactions.
+	ReadOnly bool `toml:"read_only"`
 }
 
 func (c *Config) AddInstance(instanceConfig InstanceConfig) {
@@ -81,6 +132,42 @@ func (c *InstanceConfig) GetUserConfig(userName string) (*UserConfig, error) {
 	return nil, fmt.Errorf("UserNotFound")
 }
 
+func (c *InstanceConfig) IsProduction() bool {
+	return c.Environment == Production
+}
+
+// GetTags returns the user defined tags of the instance, followed by the
+// labels imported into Params by the discovery (formatted as "key:value").
+func (c *InstanceConfig) GetTags() []string {
+	tags := slices.Clone(c.Tags)
+
+	var labels []string
+	for key, value := range c.Params {
+		labels = append(labels, fmt.Sprintf("%s:%v", key, value))
+	}
+	sort.Strings(labels)
+
+	for _, label := range labels {
+		if !slices.Contains(tags, label) {
+			tags = append(tags, label)
+		}
+	}
+
+	return tags
+}
+
+// GetGroupPath splits the instance group into its folder names.
+func (c *InstanceConfig) GetGroupPath() []string {
+	var path []string
+	for _, folder := range strings.Split(c.Group, "/") {
+		folder = strings.TrimSpace(folder)
+		if folder != "" {
+			path = append(path, folder)
+		}
+	}
+	return path
+}
+
 func GetConfigFile() (*string, error) {
 	configDir, err := os.UserConfigDir()
 	if err != nil {
@@ -92,6 +179,22 @@ func GetConfigFile() (*string, error) {
 	return &configFile, nil
 }
 
+// GetDataFile returns the path of a file stored by csql in its data directory,
+// next to the configuration file. The directory is created if needed.
+func GetDataFile(name string) (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	dataDir := filepath.Join(configDir, "csql")
+	if err := os.MkdirAll(dataDir, 0700); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dataDir, name), nil
+}
+
 func CheckConfigFile() (err error) {
 	configFile, err := GetConfigFile()
 	if err != nil {
@@ -4,12 +4,17 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
 
+	"github.com/rhariady/csql/pkg/audit"
 	"github.com/rhariady/csql/pkg/auth"
 	"github.com/rhariady/csql/pkg/config"
 	"github.com/rhariady/csql/pkg/session"
+	"github.com/rhariady/csql/pkg/snippet"
 )
 
 type PostgreSQLAdapter struct {
@@ -18,6 +23,16 @@ type PostgreSQLAdapter struct {
 	user     *config.UserConfig
 	database string
 	conn     *sql.DB
+	// readOnly opens the sessions with read-only transactions by default.
+	readOnly bool
+	// workspace is the query buffers of the query editor
+	workspace *editorWorkspace
+	// paramValues are the last values of the query parameters, by name
+	paramValues map[string]string
+	// catalogCache is the metadata of the database used for the completion
+	catalogCache catalogCache
+	// objectKind is the kind of objects listed by the object list
+	objectKind objectKind
 }
 
 func (a *PostgreSQLAdapter) openConnection() error {
@@ -33,6 +48,9 @@ func (a *PostgreSQLAdapter) openConnection() error {
 	}
 
 	connectionUri := fmt.Sprintf("postgresql://%s:%s@%s:%d/%s?sslmode=disable", a.user.Username, password, a.instance.Host, a.instance.Port, a.database)
+	for _, param := range a.runtimeParams() {
+		connectionUri += fmt.Sprintf("&%s=%s", param[0], url.QueryEscape(param[1]))
+	}
 
 	a.conn, err = sql.Open("postgres", connectionUri)
 
@@ -48,11 +66,34 @@ func (a *PostgreSQLAdapter) openConnection() error {
 	return nil
 }
 
+// runtimeParams returns the server settings applied to every session opened
+// on the instance, as (name, value) pairs.
+func (a *PostgreSQLAdapter) runtimeParams() (params [][2]string) {
+	if a.readOnly {
+		params = append(params, [2]string{"default_transaction_read_only", "on"})
+	}
+	if a.instance.StatementTimeout != "" {
+		params = append(params, [2]string{"statement_timeout", a.instance.StatementTimeout})
+	}
+	return
+}
+
+// reconnect closes the current connection pool and opens a new one, to apply
+// a change of database or of connection settings.
+func (a *PostgreSQLAdapter) reconnect() error {
+	if err := a.Close(); err != nil {
+		return err
+	}
+	return a.openConnection()
+}
+
 func (a *PostgreSQLAdapter) Connect(session *session.Session, instance *config.InstanceConfig, user *config.UserConfig, database string) error {
 	// a.session = session
 	a.instance = instance
 	a.user = user
 	a.database = database
+	// Production instances are read-only until writes are explicitly allowed
+	a.readOnly = user.ReadOnly || instance.IsProduction()
 
 	err := a.openConnection()
 
@@ -74,11 +115,32 @@ func (a *PostgreSQLAdapter) Close() (err error) {
 	return
 }
 
+// audit records a statement executed on the connection in the audit log.
+func (a *PostgreSQLAdapter) audit(s *session.Session, source string, statement string, start time.Time, rows int64, err error) {
+	entry := audit.Entry{
+		Time:       start,
+		Instance:   a.instance.Name,
+		User:       a.user.Username,
+		Database:   a.database,
+		Source:     source,
+		Statement:  statement,
+		DurationMs: time.Since(start).Milliseconds(),
+		Rows:       rows,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	if a_err := audit.Record(entry); a_err != nil {
+		s.ShowMessageAsync(fmt.Sprintf("Error writing audit log:\n%s", a_err), true)
+	}
+}
+
 func (a *PostgreSQLAdapter) InputCapture(session *session.Session, event *tcell.EventKey) *tcell.EventKey {
 	rune := event.Rune()
 	switch rune {
 	case 'q':
-		viewQuery := NewQueryEditor(a, "SELECT * FROM ")
+		viewQuery := NewQueryEditor(a, "")
 		session.SetView(viewQuery)
 		return nil
 	case 'd':
@@ -112,12 +174,50 @@ func (a *PostgreSQLAdapter) GetInfo() (info []session.Info) {
 		info = append(info, session.NewInfo("Database", a.database))
 	}
 
+	if a.readOnly {
+		info = append(info, session.NewInfo("Mode", "READ-ONLY"))
+	}
+
 	return
 }
 
+func (a *PostgreSQLAdapter) GetEnvironment() config.Environment {
+	return a.instance.Environment
+}
+
 func (a *PostgreSQLAdapter) ExecuteCommand(s *session.Session, command string) error {
+	command, args, _ := strings.Cut(strings.TrimSpace(command), " ")
+	args = strings.TrimSpace(args)
+
 	switch command {
-	case "table":
+	case "write":
+		if !a.readOnly {
+			return nil
+		}
+		if a.user.ReadOnly {
+			return fmt.Errorf("user %s is configured as read-only", a.user.Username)
+		}
+		message := fmt.Sprintf("Allow write transactions on the %s instance %s?", a.instance.Environment, a.instance.Name)
+		s.ShowAlert(message, func(s *session.Session) {
+			a.readOnly = false
+			if err := a.reconnect(); err != nil {
+				s.ShowMessage(fmt.Sprintf("Error:\n%s", err), true)
+			}
+			s.RefreshHeader()
+		}, func(s *session.Session) {})
+	case "readonly":
+		a.readOnly = true
+		if err := a.reconnect(); err != nil {
+			return err
+		}
+		s.RefreshHeader()
+	case "table", "function", "type", "extension":
+		a.objectKind = map[string]objectKind{
+			"table":     objectRelations,
+			"function":  objectFunctions,
+			"type":      objectTypes,
+			"extension": objectExtensions,
+		}[command]
 		tableList := NewTableList(a)
 		s.SetView(tableList)
 	case "role":
@@ -126,6 +226,28 @@ func (a *PostgreSQLAdapter) ExecuteCommand(s *session.Session, command string) e
 	case "database":
 		databaseList := NewDatabaseList(a)
 		s.SetView(databaseList)
+	case "history":
+		historyList := NewHistoryList(a)
+		s.SetView(historyList)
+	case "refresh":
+		a.refreshCatalog()
+		if _, err := a.getCatalog(); err != nil {
+			return err
+		}
+		s.ShowNotice("Refreshing the catalog...")
+	case "snippet":
+		queryEditor := NewQueryEditor(a, "")
+		if args == "" {
+			s.SetView(queryEditor)
+			queryEditor.showSnippets(s)
+			return nil
+		}
+		found, err := snippet.Find(s.Config.Snippets, a.instance, args)
+		if err != nil {
+			return err
+		}
+		s.SetView(queryEditor)
+		queryEditor.runSnippet(s, *found)
 	}
 
 	return nil
@@ -251,11 +373,12 @@ func (a *PostgreSQLAdapter) listTables() (tables []TableRecord, err error) {
 	ctx := context.Background()
 	rows, err := a.conn.QueryContext(ctx, `SELECT n.nspname as "Schema",
 c.relname as "Name",
-CASE c.relkind WHEN 'r' THEN 'table' WHEN 'v' THEN 'view' WHEN 'i' THEN 'index' WHEN 'S' THEN 'sequence' WHEN 's' THEN 'special' END as "Type",
+CASE c.relkind WHEN 'r' THEN 'table' WHEN 'p' THEN 'partitioned table' WHEN 'v' THEN 'view' WHEN 'm' THEN 'materialized view'
+    WHEN 'f' THEN 'foreign table' WHEN 'i' THEN 'index' WHEN 'S' THEN 'sequence' WHEN 's' THEN 'special' END as "Type",
 pg_catalog.pg_get_userbyid(c.relowner) as "Owner"
 FROM pg_catalog.pg_class c
     LEFT JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
-WHERE c.relkind IN ('r','')
+WHERE c.relkind IN ('r','p','v','m','f','S')
     AND n.nspname <> 'pg_catalog'
     AND n.nspname <> 'information_schema'
     AND n.nspname !~ '^pg_toast'
@@ -0,0 +1,142 @@
+package postgresql
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/rhariady/csql/pkg/session"
+	"github.com/rhariady/csql/pkg/workspace"
+)
+
+// editorBuffer is a query buffer of the query editor. The results of its last
+// execution are kept while the editor is open, but their cursors are released
+// while the buffer is hidden.
+type editorBuffer struct {
+	name string
+	text string
+	// start and end are the selection of the editor in the buffer, as byte
+	// offsets
+	start   int
+	end     int
+	results []*statementResult
+	current int
+}
+
+// closeResults releases the cursors of the results of the buffer.
+func (b *editorBuffer) closeResults() {
+	for _, result := range b.results {
+		result.Close()
+	}
+	b.results = nil
+	b.current = 0
+}
+
+// releaseResults releases the cursors of the results of the buffer, keeping
+// the rows fetched so far, so that a hidden buffer does not hold a
+// transaction open.
+func (b *editorBuffer) releaseResults() {
+	for _, result := range b.results {
+		if result.grid != nil {
+			result.grid.Release()
+		}
+	}
+}
+
+// editorWorkspace is the query buffers of the query editor for a database,
+// which are saved across restarts.
+type editorWorkspace struct {
+	database string
+	buffers  []*editorBuffer
+	current  int
+}
+
+// Current returns the buffer shown in the editor.
+func (w *editorWorkspace) Current() *editorBuffer {
+	return w.buffers[w.current]
+}
+
+// Add adds a buffer after the last one, and makes it the current one. It is
+// named after its position when name is empty.
+func (w *editorWorkspace) Add(name string, text string) *editorBuffer {
+	if name == "" {
+		for i := len(w.buffers) + 1; ; i++ {
+			name = fmt.Sprintf("Query %d", i)
+			if !slices.ContainsFunc(w.buffers, func(b *editorBuffer) bool { return b.name == name }) {
+				break
+			}
+		}
+	}
+
+	buffer := &editorBuffer{name: name, text: text, start: len(text), end: len(text)}
+	w.buffers = append(w.buffers, buffer)
+	w.current = len(w.buffers) - 1
+	return buffer
+}
+
+// Remove closes a buffer. The last buffer is replaced with an empty one.
+func (w *editorWorkspace) Remove(index int) {
+	w.buffers[index].closeResults()
+	w.buffers = slices.Delete(w.buffers, index, index+1)
+	if len(w.buffers) == 0 {
+		w.Add("", "")
+	}
+	w.current = min(w.current, len(w.buffers)-1)
+}
+
+// closeResults releases the cursors of the results of every buffer.
+func (w *editorWorkspace) closeResults() {
+	for _, buffer := range w.buffers {
+		buffer.closeResults()
+	}
+}
+
+// getWorkspace returns the buffers of the query editor for the database,
+// restored from the saved workspace the first time. Without saved buffers,
+// the editor opens with the last query executed on the database.
+func (a *PostgreSQLAdapter) getWorkspace(s *session.Session) *editorWorkspace {
+	if a.workspace != nil && a.workspace.database == a.database {
+		return a.workspace
+	}
+	if a.workspace != nil {
+		a.workspace.closeResults()
+	}
+
+	w := &editorWorkspace{database: a.database}
+	saved, ok, err := workspace.Load(a.instance.Name, a.database)
+	if err != nil {
+		s.ShowMessage(fmt.Sprintf("Error loading the query buffers:\n%s", err), true)
+	}
+	if ok {
+		for _, buffer := range saved.Buffers {
+			w.Add(buffer.Name, buffer.Text)
+		}
+		w.current = min(max(saved.Current, 0), len(w.buffers)-1)
+	}
+	if len(w.buffers) == 0 {
+		w.Add("", a.getEditorQuery())
+	}
+
+	a.workspace = w
+	return w
+}
+
+// saveWorkspace saves the buffers of the query editor.
+func (a *PostgreSQLAdapter) saveWorkspace(s *session.Session) {
+	w := a.workspace
+	if w == nil {
+		return
+	}
+
+	saved := workspace.Workspace{
+		Instance: a.instance.Name,
+		Database: w.database,
+		Current:  w.current,
+	}
+	for _, buffer := range w.buffers {
+		saved.Buffers = append(saved.Buffers, workspace.Buffer{Name: buffer.name, Text: buffer.text})
+	}
+
+	if err := workspace.Save(saved); err != nil {
+		s.ShowMessage(fmt.Sprintf("Error saving the query buffers:\n%s", err), true)
+	}
+}
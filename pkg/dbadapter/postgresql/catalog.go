@@ -0,0 +1,226 @@
+package postgresql
+
+import (
+	"context"
+	"sync"
+
+	"github.com/lib/pq"
+)
+
+type CatalogTable struct {
+	Schema string
+	Name   string
+	// Kind is the relkind of the relation (r: table, v: view, ...)
+	Kind string
+}
+
+type CatalogColumn struct {
+	Name string
+	Type string
+}
+
+// Catalog is the metadata of a database used by the completion of the query
+// editor. It is loaded once per database, and on demand with the refresh
+// command.
+type Catalog struct {
+	database   string
+	searchPath []string
+	schemas    []string
+	tables     []CatalogTable
+	columns    map[CatalogTable][]CatalogColumn
+	functions  []string
+	// index maps the (schema, name) pairs to the tables
+	index map[[2]string]CatalogTable
+}
+
+// catalogCache holds the catalog of the current database of an adapter.
+type catalogCache struct {
+	mu      sync.Mutex
+	catalog *Catalog
+	loading chan struct{}
+	err     error
+	// generation is incremented by each refresh, so that a load started
+	// before it drops its result
+	generation int
+}
+
+// getCatalog returns the catalog of the current database, loading it if
+// needed. It returns nil while the catalog is being loaded in the background.
+func (a *PostgreSQLAdapter) getCatalog() (*Catalog, error) {
+	a.catalogCache.mu.Lock()
+	defer a.catalogCache.mu.Unlock()
+
+	cache := &a.catalogCache
+	if cache.catalog != nil && cache.catalog.database == a.database {
+		return cache.catalog, nil
+	}
+	if cache.loading != nil {
+		return nil, nil
+	}
+	if cache.err != nil {
+		err := cache.err
+		cache.err = nil
+		return nil, err
+	}
+
+	loading := make(chan struct{})
+	cache.loading = loading
+	database := a.database
+	generation := cache.generation
+	go func() {
+		catalog, err := a.loadCatalog(database)
+
+		cache.mu.Lock()
+		defer cache.mu.Unlock()
+		close(loading)
+		if cache.generation != generation {
+			return
+		}
+		cache.catalog = catalog
+		cache.err = err
+		cache.loading = nil
+	}()
+
+	return nil, nil
+}
+
+// refreshCatalog drops the cached catalog, so that it is loaded again. The
+// result of a load still running is dropped.
+func (a *PostgreSQLAdapter) refreshCatalog() {
+	a.catalogCache.mu.Lock()
+	defer a.catalogCache.mu.Unlock()
+	a.catalogCache.generation++
+	a.catalogCache.catalog = nil
+	a.catalogCache.loading = nil
+	a.catalogCache.err = nil
+}
+
+func (a *PostgreSQLAdapter) loadCatalog(database string) (catalog *Catalog, err error) {
+	ctx := context.Background()
+	catalog = &Catalog{
+		database: database,
+		columns:  make(map[CatalogTable][]CatalogColumn),
+		index:    make(map[[2]string]CatalogTable),
+	}
+
+	if err := a.conn.QueryRowContext(ctx, "SELECT current_schemas(true)").Scan(pq.Array(&catalog.searchPath)); err != nil {
+		return nil, err
+	}
+
+	rows, err := a.conn.QueryContext(ctx, `SELECT nspname FROM pg_catalog.pg_namespace
+		WHERE nspname NOT LIKE 'pg\_toast%' AND nspname NOT LIKE 'pg\_temp\_%'
+		ORDER BY nspname`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var schema string
+		if err := rows.Scan(&schema); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		catalog.schemas = append(catalog.schemas, schema)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+
+	rows, err = a.conn.QueryContext(ctx, `SELECT n.nspname, c.relname, c.relkind, a.attname, pg_catalog.format_type(a.atttypid, a.atttypmod)
+		FROM pg_catalog.pg_class c
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		LEFT JOIN pg_catalog.pg_attribute a ON a.attrelid = c.oid AND a.attnum > 0 AND NOT a.attisdropped
+		WHERE c.relkind IN ('r', 'v', 'm', 'f', 'p')
+		ORDER BY n.nspname, c.relname, a.attnum`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var table CatalogTable
+		var column, columnType *string
+		if err := rows.Scan(&table.Schema, &table.Name, &table.Kind, &column, &columnType); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if _, ok := catalog.columns[table]; !ok {
+			catalog.tables = append(catalog.tables, table)
+			catalog.columns[table] = nil
+			catalog.index[[2]string{table.Schema, table.Name}] = table
+		}
+		if column != nil {
+			catalog.columns[table] = append(catalog.columns[table], CatalogColumn{Name: *column, Type: *columnType})
+		}
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+
+	rows, err = a.conn.QueryContext(ctx, `SELECT DISTINCT p.proname FROM pg_catalog.pg_proc p ORDER BY p.proname`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var function string
+		if err := rows.Scan(&function); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		catalog.functions = append(catalog.functions, function)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+
+	return catalog, nil
+}
+
+// FindTable resolves a table name, qualified by its schema or not. Unqualified
+// names are searched in the schemas of the search path.
+func (c *Catalog) FindTable(schema string, name string) (CatalogTable, bool) {
+	schemas := c.searchPath
+	if schema != "" {
+		schemas = []string{schema}
+	}
+
+	for _, schema := range schemas {
+		if table, ok := c.index[[2]string{schema, name}]; ok {
+			return table, true
+		}
+	}
+
+	return CatalogTable{}, false
+}
+
+// GetColumns returns the columns of a table.
+func (c *Catalog) GetColumns(table CatalogTable) []CatalogColumn {
+	return c.columns[table]
+}
+
+// GetTables returns the tables of a schema, or of all the schemas when schema
+// is empty.
+func (c *Catalog) GetTables(schema string) []CatalogTable {
+	if schema == "" {
+		return c.tables
+	}
+
+	var tables []CatalogTable
+	for _, table := range c.tables {
+		if table.Schema == schema {
+			tables = append(tables, table)
+		}
+	}
+	return tables
+}
+
+// IsVisible reports whether a table can be referenced without its schema.
+func (c *Catalog) IsVisible(table CatalogTable) bool {
+	found, ok := c.FindTable("", table.Name)
+	return ok && found == table
+}
+
+// catalogLoading returns a channel closed when the catalog being loaded is
+// available, or nil when no catalog is being loaded.
+func (a *PostgreSQLAdapter) catalogLoading() <-chan struct{} {
+	a.catalogCache.mu.Lock()
+	defer a.catalogCache.mu.Unlock()
+	return a.catalogCache.loading
+}
@@ -48,7 +48,7 @@ func (d *ChangeDatabaseModal) GetContent(session *session.Session) tview.Primiti
 		newDatabase := databaseTable.GetCell(row, 0).Text
 
 		d.database = newDatabase
-		err := d.openConnection()
+		err := d.reconnect()
 
 		if err != nil {
 			session.ShowMessage(fmt.Sprintf("Error:\n%s", err), true)
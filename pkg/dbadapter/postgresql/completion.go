@@ -0,0 +1,422 @@
+package postgresql
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/lib/pq"
+	"github.com/rivo/tview"
+
+	"github.com/rhariady/csql/pkg/session"
+	"github.com/rhariady/csql/pkg/snippet"
+	"github.com/rhariady/csql/pkg/sqlutil"
+)
+
+const completionHeight = 10
+
+type completionCandidate struct {
+	// Text is the name shown in the popup and matched with the typed prefix
+	Text string
+	// Insert replaces the typed prefix when the candidate is accepted
+	Insert string
+	Kind   string
+	Detail string
+}
+
+var simpleIdentifier = regexp.MustCompile(`^[a-z_][a-z0-9_$]*$`)
+
+// quoteIdentifier quotes an identifier only when it cannot be written as is.
+func quoteIdentifier(name string) string {
+	if simpleIdentifier.MatchString(name) && !sqlutil.IsKeyword(name) {
+		return name
+	}
+	return pq.QuoteIdentifier(name)
+}
+
+func tableKind(table CatalogTable) string {
+	switch table.Kind {
+	case "v":
+		return "view"
+	case "m":
+		return "matview"
+	case "f":
+		return "foreign"
+	default:
+		return "table"
+	}
+}
+
+// getCompletions returns the candidates for the word described by context,
+// before filtering them with the typed prefix. The catalog is nil while it is
+// being loaded, in which case only keywords and snippets are suggested.
+func getCompletions(catalog *Catalog, text string, context sqlutil.CompletionContext, snippets []snippet.Snippet) []completionCandidate {
+	var candidates []completionCandidate
+
+	addColumns := func(table CatalogTable) {
+		for _, column := range catalog.GetColumns(table) {
+			candidates = append(candidates, completionCandidate{
+				Text:   column.Name,
+				Insert: quoteIdentifier(column.Name),
+				Kind:   "column",
+				Detail: column.Type,
+			})
+		}
+	}
+
+	addTables := func(tables []CatalogTable, qualified bool) {
+		for _, table := range tables {
+			insert := quoteIdentifier(table.Name)
+			if qualified && !catalog.IsVisible(table) {
+				insert = fmt.Sprintf("%s.%s", quoteIdentifier(table.Schema), insert)
+			}
+			candidates = append(candidates, completionCandidate{
+				Text:   table.Name,
+				Insert: insert,
+				Kind:   tableKind(table),
+				Detail: table.Schema,
+			})
+		}
+	}
+
+	addSchemas := func() {
+		for _, schema := range catalog.schemas {
+			candidates = append(candidates, completionCandidate{
+				Text:   schema,
+				Insert: quoteIdentifier(schema),
+				Kind:   "schema",
+			})
+		}
+	}
+
+	if context.Qualifier != "" {
+		if catalog == nil {
+			return nil
+		}
+
+		// The qualifier is an alias, a table or a schema
+		for _, reference := range sqlutil.TableReferences(text) {
+			if reference.Alias == context.Qualifier || (reference.Alias == "" && reference.Name == context.Qualifier) {
+				if table, ok := catalog.FindTable(reference.Schema, reference.Name); ok {
+					addColumns(table)
+					return candidates
+				}
+			}
+		}
+		if table, ok := catalog.FindTable("", context.Qualifier); ok {
+			addColumns(table)
+		}
+		if slices.Contains(catalog.schemas, context.Qualifier) {
+			addTables(catalog.GetTables(context.Qualifier), false)
+		}
+		return candidates
+	}
+
+	if catalog != nil {
+		if context.Relation {
+			addTables(catalog.GetTables(""), true)
+			addSchemas()
+			return candidates
+		}
+
+		for _, reference := range sqlutil.TableReferences(text) {
+			if table, ok := catalog.FindTable(reference.Schema, reference.Name); ok {
+				addColumns(table)
+			}
+		}
+		addTables(catalog.GetTables(""), true)
+		addSchemas()
+	}
+
+	for _, item := range snippets {
+		candidates = append(candidates, completionCandidate{
+			Text:   item.Name,
+			Insert: item.Query,
+			Kind:   "snippet",
+			Detail: item.Description,
+		})
+	}
+
+	// Keywords follow the case of the typed prefix
+	lower := context.Prefix != "" && context.Prefix == strings.ToLower(context.Prefix)
+	for _, keyword := range sqlutil.Keywords {
+		insert := keyword
+		if lower {
+			insert = strings.ToLower(keyword)
+		}
+		candidates = append(candidates, completionCandidate{
+			Text:   keyword,
+			Insert: insert,
+			Kind:   "keyword",
+		})
+	}
+
+	if catalog != nil {
+		for _, function := range catalog.functions {
+			candidates = append(candidates, completionCandidate{
+				Text:   function,
+				Insert: quoteIdentifier(function),
+				Kind:   "function",
+			})
+		}
+	}
+
+	return candidates
+}
+
+// filterCompletions keeps the candidates matching the typed prefix, those
+// starting with it first, then the fuzzy matches.
+func filterCompletions(candidates []completionCandidate, prefix string) []completionCandidate {
+	var prefixMatches, fuzzyMatches []completionCandidate
+	seen := make(map[completionCandidate]bool)
+
+	prefix = strings.ToLower(prefix)
+	for _, candidate := range candidates {
+		if seen[candidate] {
+			continue
+		}
+		seen[candidate] = true
+
+		switch {
+		case strings.HasPrefix(strings.ToLower(candidate.Text), prefix):
+			prefixMatches = append(prefixMatches, candidate)
+		case session.FuzzyMatch(prefix, candidate.Text):
+			fuzzyMatches = append(fuzzyMatches, candidate)
+		}
+	}
+
+	return append(prefixMatches, fuzzyMatches...)
+}
+
+// completionPopup suggests completions for the word under the cursor of the
+// query editor, in a list drawn over the editor.
+type completionPopup struct {
+	adapter    *PostgreSQLAdapter
+	session    *session.Session
+	input      *tview.TextArea
+	table      *tview.Table
+	open       bool
+	accepting  bool
+	waiting    bool
+	context    sqlutil.CompletionContext
+	candidates []completionCandidate
+	snippets   []snippet.Snippet
+}
+
+func newCompletionPopup(adapter *PostgreSQLAdapter, s *session.Session, input *tview.TextArea) *completionPopup {
+	table := tview.NewTable().
+		SetBorders(false).
+		SetSelectable(true, false)
+	table.SetBorder(true)
+
+	popup := &completionPopup{
+		adapter: adapter,
+		session: s,
+		input:   input,
+		table:   table,
+	}
+
+	input.SetChangedFunc(func() {
+		if popup.accepting {
+			return
+		}
+		if popup.open {
+			popup.update()
+			return
+		}
+		// Complete the members of a qualifier as soon as the dot is typed
+		text := input.GetText()
+		if _, _, end := input.GetSelection(); end > 0 && end <= len(text) && text[end-1] == '.' {
+			popup.Open()
+		}
+	})
+	input.SetMovedFunc(func() {
+		if popup.open && !popup.accepting {
+			popup.update()
+		}
+	})
+
+	return popup
+}
+
+// Open shows the candidates for the word under the cursor.
+func (p *completionPopup) Open() {
+	snippets, err := snippet.Load(p.session.Config.Snippets, p.adapter.instance)
+	if err != nil {
+		p.session.ShowMessage(fmt.Sprintf("Error reading snippets:\n%s", err), true)
+	}
+	p.snippets = snippets
+
+	p.open = true
+	p.update()
+}
+
+func (p *completionPopup) Close() {
+	p.open = false
+	p.candidates = nil
+	p.table.Clear()
+}
+
+func (p *completionPopup) IsOpen() bool {
+	return p.open
+}
+
+// update computes the candidates again, after the text or the cursor moved.
+func (p *completionPopup) update() {
+	text, _, end := p.input.GetSelection()
+	if text != "" {
+		p.Close()
+		return
+	}
+
+	catalog, err := p.adapter.getCatalog()
+	if err != nil {
+		p.Close()
+		p.session.ShowMessage(fmt.Sprintf("Error loading the catalog:\n%s", err), true)
+		return
+	}
+	if catalog == nil {
+		p.waitCatalog()
+	}
+
+	text = p.input.GetText()
+	p.context = sqlutil.GetCompletionContext(text, end)
+	candidates := getCompletions(catalog, text, p.context, p.snippets)
+	p.candidates = filterCompletions(candidates, p.context.Prefix)
+	if len(p.candidates) == 0 {
+		p.Close()
+		return
+	}
+	p.table.Clear()
+	for i, candidate := range p.candidates {
+		p.table.SetCell(i, 0, tview.NewTableCell(tview.Escape(candidate.Text)))
+		p.table.SetCell(i, 1, tview.NewTableCell(candidate.Kind).SetTextColor(tcell.ColorGray))
+		p.table.SetCell(i, 2, tview.NewTableCell(tview.Escape(candidate.Detail)).
+			SetTextColor(tcell.ColorGray).
+			SetMaxWidth(valueMaxWidth))
+	}
+	p.table.Select(0, 0).ScrollToBeginning()
+
+	p.table.SetTitle("")
+	if catalog == nil {
+		p.table.SetTitle("Loading catalog...")
+	}
+}
+
+// waitCatalog updates the candidates once the catalog being loaded is
+// available.
+func (p *completionPopup) waitCatalog() {
+	loading := p.adapter.catalogLoading()
+	if loading == nil || p.waiting {
+		return
+	}
+
+	p.waiting = true
+	go func() {
+		<-loading
+		p.session.App.QueueUpdateDraw(func() {
+			p.waiting = false
+			if p.open {
+				p.update()
+			}
+		})
+	}()
+}
+
+// accept replaces the typed prefix with the selected candidate.
+func (p *completionPopup) accept() {
+	row, _ := p.table.GetSelection()
+	if row < 0 || row >= len(p.candidates) {
+		p.Close()
+		return
+	}
+	candidate := p.candidates[row]
+
+	_, _, end := p.input.GetSelection()
+	start := p.context.Start
+	if start > 0 && p.input.GetText()[start-1] == '"' && strings.HasPrefix(candidate.Insert, `"`) {
+		start--
+	}
+
+	p.accepting = true
+	p.input.Replace(start, end, candidate.Insert)
+	p.accepting = false
+	p.Close()
+}
+
+// HandleKey navigates the candidates while the popup is open. It reports
+// whether the key was consumed.
+func (p *completionPopup) HandleKey(event *tcell.EventKey) bool {
+	if !p.open {
+		return false
+	}
+
+	row, _ := p.table.GetSelection()
+	count := len(p.candidates)
+	switch event.Key() {
+	case tcell.KeyUp:
+		p.table.Select((row-1+count)%count, 0)
+	case tcell.KeyDown:
+		p.table.Select((row+1)%count, 0)
+	case tcell.KeyPgUp:
+		p.table.Select(max(row-completionHeight, 0), 0)
+	case tcell.KeyPgDn:
+		p.table.Select(min(row+completionHeight, count-1), 0)
+	case tcell.KeyEnter, tcell.KeyTab:
+		p.accept()
+	case tcell.KeyEsc:
+		p.Close()
+	default:
+		return false
+	}
+	return true
+}
+
+// Draw draws the popup under the cursor of the editor, or above it when
+// there is not enough room below.
+func (p *completionPopup) Draw(screen tcell.Screen) {
+	if !p.open || len(p.candidates) == 0 {
+		return
+	}
+
+	widths := make([]int, 3)
+	for _, candidate := range p.candidates {
+		widths[0] = max(widths[0], tview.TaggedStringWidth(tview.Escape(candidate.Text)))
+		widths[1] = max(widths[1], len(candidate.Kind))
+		widths[2] = max(widths[2], min(tview.TaggedStringWidth(tview.Escape(candidate.Detail)), valueMaxWidth))
+	}
+	width := widths[0] + widths[1] + widths[2] + 4
+	height := min(len(p.candidates), completionHeight) + 2
+
+	screenWidth, screenHeight := screen.Size()
+	inputX, inputY, _, _ := p.input.GetInnerRect()
+	rowOffset, columnOffset := p.input.GetOffset()
+	_, _, row, column := p.input.GetCursor()
+	cursorX := inputX + column - columnOffset
+	cursorY := inputY + row - rowOffset
+
+	x := cursorX - tview.TaggedStringWidth(tview.Escape(p.context.Prefix)) - 1
+	y := cursorY + 1
+	if y+height > screenHeight && cursorY-height >= 0 {
+		y = cursorY - height
+	}
+	width = min(width, screenWidth)
+	x = max(min(x, screenWidth-width), 0)
+
+	p.table.SetRect(x, y, width, height)
+	p.table.Draw(screen)
+}
+
+// completionLayout draws the completion popup over the layout of the query
+// editor.
+type completionLayout struct {
+	*tview.Flex
+	popup *completionPopup
+}
+
+func (l *completionLayout) Draw(screen tcell.Screen) {
+	l.Flex.Draw(screen)
+	l.popup.Draw(screen)
+}
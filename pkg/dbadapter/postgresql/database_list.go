@@ -26,40 +26,48 @@ func (d *DatabaseList) GetTitle() string {
 	return "Databases"
 }
 
-func (d *DatabaseList) GetContent(session *session.Session) tview.Primitive {
+func (d *DatabaseList) GetContent(s *session.Session) tview.Primitive {
 	// Table for databases
 	databaseTable := tview.NewTable().
 		SetBorders(false).
 		SetSelectable(true, false)
 
-	databaseTable.SetCell(0, 0, tview.NewTableCell("Name").SetSelectable(false).SetExpansion(1))
-	databaseTable.SetCell(0, 1, tview.NewTableCell("Owner").SetSelectable(false))
-	databaseTable.SetCell(0, 2, tview.NewTableCell("Encoding").SetSelectable(false))
-	databaseTable.SetCell(0, 3, tview.NewTableCell("Collate").SetSelectable(false))
-	databaseTable.SetCell(0, 4, tview.NewTableCell("Ctype").SetSelectable(false))
-	databaseTable.SetCell(0, 5, tview.NewTableCell("Access Privileges").SetSelectable(false))
+	filterTable := session.NewFilterTable(databaseTable)
+	filterTable.SetHeader(
+		tview.NewTableCell("Name").SetExpansion(1),
+		tview.NewTableCell("Owner"),
+		tview.NewTableCell("Encoding"),
+		tview.NewTableCell("Collate"),
+		tview.NewTableCell("Ctype"),
+		tview.NewTableCell("Access Privileges"),
+	)
 	databaseTable.SetCell(1, 0, tview.NewTableCell("Loading databases..."))
 
 	// Get databases
 	go func() {
 		databases, err := d.listDatabases()
 		if err != nil {
-			session.ShowMessageAsync(fmt.Sprintf("Error:\n%s", err), true)
+			s.ShowMessageAsync(fmt.Sprintf("Error:\n%s", err), true)
 			return
 		}
 
 		// Populate table
-		for i, db := range databases {
-			databaseTable.SetCell(i+1, 0, tview.NewTableCell(db.Name))
-			databaseTable.SetCell(i+1, 1, tview.NewTableCell(db.Owner))
-			databaseTable.SetCell(i+1, 2, tview.NewTableCell(db.Encoding))
-			databaseTable.SetCell(i+1, 3, tview.NewTableCell(db.Collate))
-			databaseTable.SetCell(i+1, 4, tview.NewTableCell(db.Ctype))
-			databaseTable.SetCell(i+1, 5, tview.NewTableCell(db.AccessPrivileges))
+		var rows [][]*tview.TableCell
+		for _, db := range databases {
+			rows = append(rows, []*tview.TableCell{
+				tview.NewTableCell(db.Name),
+				tview.NewTableCell(db.Owner),
+				tview.NewTableCell(db.Encoding),
+				tview.NewTableCell(db.Collate),
+				tview.NewTableCell(db.Ctype),
+				tview.NewTableCell(db.AccessPrivileges),
+			})
 		}
 
 		// On selection, go to table
-		session.App.Draw()
+		s.App.QueueUpdateDraw(func() {
+			filterTable.SetRows(rows)
+		})
 	}()
 
 	databaseTable.SetSelectedFunc(func(row int, column int) {
@@ -68,19 +76,24 @@ func (d *DatabaseList) GetContent(session *session.Session) tview.Primitive {
 		}
 		d.database = databaseTable.GetCell(row, 0).Text
 		tableList := NewTableList(d.PostgreSQLAdapter)
-		session.SetView(tableList)
+		s.SetView(tableList)
 	})
 
 	databaseTable.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		return d.InputCapture(session, event)
+		if event.Rune() == 'f' {
+			filterTable.Show(s)
+			return nil
+		}
+		return d.InputCapture(s, event)
 	})
 
-	return databaseTable
+	return filterTable
 }
 
 func (i *DatabaseList) GetKeyBindings() (keybindings []*session.KeyBinding) {
 	keybindings = []*session.KeyBinding{
 		session.NewKeyBinding("<enter>", "List database tables"),
+		session.NewKeyBinding("[f]", "Filter databases"),
 	}
 
 	base_keybinding := i.PostgreSQLAdapter.GetKeyBindings()
@@ -0,0 +1,426 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/rhariady/csql/pkg/session"
+	"github.com/rhariady/csql/pkg/sqlutil"
+)
+
+// The thresholds of the highlighting of the plan nodes
+const (
+	// planHotShare and planWarmShare are the shares of the time of the
+	// statement, or of its cost without ANALYZE, spent in a node itself
+	planHotShare  = 0.5
+	planWarmShare = 0.2
+	// planMisestimate is the factor between the estimated and the actual rows
+	// of a node from which it is a misestimate
+	planMisestimate = 10
+)
+
+// explainSavepoint is the savepoint in which statements are explained in the
+// transaction of the query editor, so that an error does not abort it.
+const explainSavepoint = "csql_explain"
+
+// planNode is a node of a query plan.
+type planNode struct {
+	properties map[string]any
+	children   []*planNode
+	// total is the time spent in the node and its children, for all its
+	// loops, or their cost without ANALYZE. self excludes the children.
+	total float64
+	self  float64
+}
+
+func newPlanNode(properties map[string]any, analyze bool) *planNode {
+	node := &planNode{properties: properties}
+
+	children, _ := properties["Plans"].([]any)
+	delete(properties, "Plans")
+
+	childTotal := 0.0
+	for _, child := range children {
+		if properties, ok := child.(map[string]any); ok {
+			childNode := newPlanNode(properties, analyze)
+			node.children = append(node.children, childNode)
+			childTotal += childNode.total
+		}
+	}
+
+	if analyze {
+		node.total = node.number("Actual Total Time") * node.number("Actual Loops")
+	} else {
+		node.total = node.number("Total Cost")
+	}
+	node.self = max(node.total-childTotal, 0)
+
+	return node
+}
+
+func (n *planNode) number(name string) float64 {
+	value, _ := n.properties[name].(float64)
+	return value
+}
+
+func (n *planNode) text(name string) string {
+	value, _ := n.properties[name].(string)
+	return value
+}
+
+// getName returns the operation of the node, named as by EXPLAIN in text
+// format, e.g. "Index Scan using users_pkey on users u".
+func (n *planNode) getName() string {
+	name := n.text("Node Type")
+	if join := n.text("Join Type"); join != "" && join != "Inner" {
+		if base, ok := strings.CutSuffix(name, " Join"); ok {
+			name = fmt.Sprintf("%s %s Join", base, join)
+		} else {
+			name = fmt.Sprintf("%s %s Join", name, join)
+		}
+	}
+	if index := n.text("Index Name"); index != "" {
+		name += " using " + index
+	}
+
+	relation := n.text("Relation Name")
+	if schema := n.text("Schema"); schema != "" && relation != "" {
+		relation = schema + "." + relation
+	}
+	for _, object := range []string{"CTE Name", "Function Name"} {
+		if relation == "" {
+			relation = n.text(object)
+		}
+	}
+	if relation != "" {
+		name += " on " + relation
+		if alias := n.text("Alias"); alias != "" && alias != n.text("Relation Name") && alias != relation {
+			name += " " + alias
+		}
+	}
+
+	if subplan := n.text("Subplan Name"); subplan != "" {
+		name = subplan + ": " + name
+	}
+	return name
+}
+
+// getMisestimate returns the factor between the estimated and the actual rows
+// of the node, and whether the rows were underestimated.
+func (n *planNode) getMisestimate() (float64, bool) {
+	estimated, actual := n.number("Plan Rows"), n.number("Actual Rows")
+	factor := max(estimated, actual) / max(min(estimated, actual), 1)
+	return factor, actual > estimated
+}
+
+// queryPlan is the plan of a statement, from EXPLAIN (FORMAT JSON).
+type queryPlan struct {
+	root          *planNode
+	analyze       bool
+	planningTime  float64
+	executionTime float64
+	// rolledBack is set when the changes of the statement were rolled back
+	rolledBack bool
+}
+
+func parsePlan(output string, analyze bool) (*queryPlan, error) {
+	var explained []struct {
+		Plan          map[string]any `json:"Plan"`
+		PlanningTime  float64        `json:"Planning Time"`
+		ExecutionTime float64        `json:"Execution Time"`
+	}
+	if err := json.Unmarshal([]byte(output), &explained); err != nil {
+		return nil, fmt.Errorf("invalid plan: %w", err)
+	}
+	if len(explained) == 0 || explained[0].Plan == nil {
+		return nil, errors.New("invalid plan: no plan node")
+	}
+
+	return &queryPlan{
+		root:          newPlanNode(explained[0].Plan, analyze),
+		analyze:       analyze,
+		planningTime:  explained[0].PlanningTime,
+		executionTime: explained[0].ExecutionTime,
+	}, nil
+}
+
+// getShare returns the share of the time of the statement, or of its cost
+// without ANALYZE, spent in a node itself.
+func (p *queryPlan) getShare(node *planNode) float64 {
+	if p.root.total <= 0 {
+		return 0
+	}
+	return node.self / p.root.total
+}
+
+// explainQuery returns the EXPLAIN statement of a statement.
+func explainQuery(statement string, analyze bool) string {
+	options := "FORMAT JSON"
+	if analyze {
+		options = "ANALYZE, BUFFERS, FORMAT JSON"
+	}
+	statement = strings.TrimSuffix(strings.TrimSpace(statement), ";")
+	return fmt.Sprintf("EXPLAIN (%s) %s", options, statement)
+}
+
+// explainStatement returns the plan of a statement. In the transaction of the
+// query editor, when tx is not nil, it is explained in a savepoint, so that an
+// error does not abort the transaction. With ANALYZE, the statement is
+// executed: the changes of a write statement are rolled back, with the
+// savepoint or else in a transaction of its own.
+func (a *PostgreSQLAdapter) explainStatement(ctx context.Context, s *session.Session, statement string, analyze bool, tx *sql.Tx) (*queryPlan, error) {
+	query := explainQuery(statement, analyze)
+	kind := sqlutil.Classify(statement)
+	rollback := analyze && (kind == sqlutil.Write || kind == sqlutil.DDL)
+
+	start := time.Now()
+	var output string
+	var err error
+	switch {
+	case tx != nil:
+		output, err = explainInSavepoint(ctx, tx, query)
+	case rollback:
+		output, err = a.explainInTransaction(ctx, query)
+	default:
+		output, err = queryPlanOutput(ctx, a.conn, query)
+	}
+	a.audit(s, "explain", query, start, 0, err)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := parsePlan(output, analyze)
+	if err != nil {
+		return nil, err
+	}
+	plan.rolledBack = rollback
+	return plan, nil
+}
+
+func queryPlanOutput(ctx context.Context, conn queryer, query string) (string, error) {
+	rows, err := conn.QueryContext(ctx, query)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return "", err
+		}
+		return "", errors.New("EXPLAIN returned no plan")
+	}
+	var output string
+	if err := rows.Scan(&output); err != nil {
+		return "", err
+	}
+	return output, rows.Close()
+}
+
+func (a *PostgreSQLAdapter) explainInTransaction(ctx context.Context, query string) (string, error) {
+	tx, err := a.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	return queryPlanOutput(ctx, tx, query)
+}
+
+func explainInSavepoint(ctx context.Context, tx *sql.Tx, query string) (string, error) {
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT "+explainSavepoint); err != nil {
+		return "", err
+	}
+
+	output, err := queryPlanOutput(ctx, tx, query)
+
+	// The savepoint is released even when the statement was cancelled
+	for _, statement := range []string{"ROLLBACK TO SAVEPOINT ", "RELEASE SAVEPOINT "} {
+		if _, s_err := tx.ExecContext(context.Background(), statement+explainSavepoint); err == nil {
+			err = s_err
+		}
+	}
+	return output, err
+}
+
+// PlanView shows the plan of a statement as a tree of its nodes, with the
+// properties of the selected node. The nodes in which most of the time is
+// spent, or most of the cost without ANALYZE, and the big misestimates of
+// rows are highlighted.
+type PlanView struct {
+	*PostgreSQLAdapter
+	plan    *queryPlan
+	tree    *tview.TreeView
+	details *tview.TextView
+}
+
+func NewPlanView(adapter *PostgreSQLAdapter, plan *queryPlan) *PlanView {
+	return &PlanView{
+		PostgreSQLAdapter: adapter,
+		plan:              plan,
+	}
+}
+
+func (p *PlanView) GetTitle() string {
+	if !p.plan.analyze {
+		return "Plan"
+	}
+
+	title := fmt.Sprintf("Plan (planning %.3f ms, execution %.3f ms)", p.plan.planningTime, p.plan.executionTime)
+	if p.plan.rolledBack {
+		title += ", changes rolled back"
+	}
+	return title
+}
+
+func (p *PlanView) GetContent(s *session.Session) tview.Primitive {
+	root := p.newTreeNode(p.plan.root)
+
+	p.tree = tview.NewTreeView().
+		SetRoot(root).
+		SetCurrentNode(root)
+	p.tree.SetBorder(true)
+
+	p.details = tview.NewTextView().
+		SetDynamicColors(true).
+		SetWrap(true)
+	p.details.SetBorder(true)
+
+	p.tree.SetChangedFunc(func(node *tview.TreeNode) {
+		if planNode, ok := node.GetReference().(*planNode); ok {
+			p.showDetails(planNode)
+		}
+	})
+	p.showDetails(p.plan.root)
+
+	layout := tview.NewFlex().
+		AddItem(p.tree, 0, 2, true).
+		AddItem(p.details, 0, 1, false)
+
+	layout.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyTab {
+			if p.tree.HasFocus() {
+				s.App.SetFocus(p.details)
+			} else {
+				s.App.SetFocus(p.tree)
+			}
+			return nil
+		}
+		return event
+	})
+
+	return layout
+}
+
+func (p *PlanView) newTreeNode(node *planNode) *tview.TreeNode {
+	treeNode := tview.NewTreeNode(p.getLabel(node)).
+		SetReference(node).
+		SetExpanded(true)
+	treeNode.SetSelectedFunc(func() {
+		treeNode.SetExpanded(!treeNode.IsExpanded())
+	})
+
+	share := p.plan.getShare(node)
+	switch {
+	case share >= planHotShare:
+		treeNode.SetColor(tcell.ColorRed)
+	case share >= planWarmShare:
+		treeNode.SetColor(tcell.ColorOrange)
+	}
+
+	for _, child := range node.children {
+		treeNode.AddChild(p.newTreeNode(child))
+	}
+	return treeNode
+}
+
+// getLabel describes a node in one line, like EXPLAIN in text format, with the
+// share of the statement spent in the node.
+func (p *PlanView) getLabel(node *planNode) string {
+	label := fmt.Sprintf("%s  (cost=%.2f..%.2f rows=%.0f)",
+		tview.Escape(node.getName()), node.number("Startup Cost"), node.number("Total Cost"), node.number("Plan Rows"))
+	if !p.plan.analyze {
+		return fmt.Sprintf("%s  %.0f%%", label, p.plan.getShare(node)*100)
+	}
+
+	if node.number("Actual Loops") == 0 {
+		return label + "  [gray](never executed)[-]"
+	}
+
+	label += fmt.Sprintf("  (actual=%.3f..%.3f ms rows=%.0f loops=%.0f)  %.0f%%",
+		node.number("Actual Startup Time"), node.number("Actual Total Time"), node.number("Actual Rows"), node.number("Actual Loops"),
+		p.plan.getShare(node)*100)
+
+	if factor, under := node.getMisestimate(); factor >= planMisestimate {
+		estimate := "overestimated"
+		if under {
+			estimate = "underestimated"
+		}
+		label += fmt.Sprintf("  [yellow]rows %s ×%.0f[-]", estimate, factor)
+	}
+	return label
+}
+
+// showDetails lists the properties of a node.
+func (p *PlanView) showDetails(node *planNode) {
+	p.details.SetTitle(tview.Escape(node.text("Node Type")))
+
+	var lines []string
+	share := fmt.Sprintf("%.1f%%", p.plan.getShare(node)*100)
+	if p.plan.analyze {
+		lines = append(lines, fmt.Sprintf("[yellow]Self Time:[-] %.3f ms (%s)", node.self, share))
+	} else {
+		lines = append(lines, fmt.Sprintf("[yellow]Self Cost:[-] %.2f (%s)", node.self, share))
+	}
+
+	names := make([]string, 0, len(node.properties))
+	for name := range node.properties {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		lines = append(lines, fmt.Sprintf("[yellow]%s:[-] %s", tview.Escape(name), tview.Escape(formatPlanValue(node.properties[name]))))
+	}
+	p.details.SetText(strings.Join(lines, "\n"))
+	p.details.ScrollToBeginning()
+}
+
+func formatPlanValue(value any) string {
+	switch value := value.(type) {
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case []any:
+		var items []string
+		for _, item := range value {
+			items = append(items, formatPlanValue(item))
+		}
+		return strings.Join(items, ", ")
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+func (p *PlanView) GetKeyBindings() (keybindings []*session.KeyBinding) {
+	keybindings = []*session.KeyBinding{
+		session.NewKeyBinding("<enter>", "Expand/collapse node"),
+		session.NewKeyBinding("<tab>", "Switch focus"),
+	}
+	return
+}
+
+func (p *PlanView) GetInfo() (info []session.Info) {
+	return
+}
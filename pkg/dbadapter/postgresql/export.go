@@ -0,0 +1,510 @@
+package postgresql
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/lib/pq"
+	"github.com/rivo/tview"
+
+	"github.com/rhariady/csql/pkg/session"
+	"github.com/rhariady/csql/pkg/sqlutil"
+)
+
+type ExportFormat = string
+
+const (
+	ExportCSV      ExportFormat = "csv"
+	ExportTSV      ExportFormat = "tsv"
+	ExportJSON     ExportFormat = "json"
+	ExportNDJSON   ExportFormat = "ndjson"
+	ExportMarkdown ExportFormat = "markdown"
+	ExportInsert   ExportFormat = "insert"
+)
+
+var ExportFormats = []ExportFormat{ExportCSV, ExportTSV, ExportJSON, ExportNDJSON, ExportMarkdown, ExportInsert}
+
+// exportProgressInterval is the number of rows between two updates of the
+// progress message.
+const exportProgressInterval = 1000
+
+// resultWriter writes the rows of a result to a file in an export format.
+type resultWriter interface {
+	WriteHeader(columns []resultColumn) error
+	WriteRow(record resultRow) error
+	// Flush writes the end of the file.
+	Flush() error
+}
+
+func newResultWriter(format ExportFormat, w io.Writer, table string) (resultWriter, error) {
+	switch format {
+	case ExportCSV:
+		return &csvWriter{writer: csv.NewWriter(w)}, nil
+	case ExportTSV:
+		writer := csv.NewWriter(w)
+		writer.Comma = '\t'
+		return &csvWriter{writer: writer}, nil
+	case ExportJSON:
+		return &jsonWriter{w: w, array: true}, nil
+	case ExportNDJSON:
+		return &jsonWriter{w: w}, nil
+	case ExportMarkdown:
+		return &markdownWriter{w: w}, nil
+	case ExportInsert:
+		return &insertWriter{w: w, table: table}, nil
+	default:
+		return nil, fmt.Errorf("unknown export format %q, expected one of: %s", format, strings.Join(ExportFormats, ", "))
+	}
+}
+
+// exportExtension returns the file extension of an export format.
+func exportExtension(format ExportFormat) string {
+	switch format {
+	case ExportMarkdown:
+		return ".md"
+	case ExportInsert:
+		return ".sql"
+	default:
+		return "." + format
+	}
+}
+
+// exportText returns the text of a value in the text export formats. NULL is
+// exported as an empty string, like COPY ... CSV does.
+func (c resultColumn) exportText(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []byte:
+		if c.Type == "bytea" {
+			return `\x` + hex.EncodeToString(v)
+		}
+		return string(v)
+	default:
+		return c.formatValue(value, false)
+	}
+}
+
+// exportJSON returns the JSON value of a value.
+func (c resultColumn) exportJSON(value any) any {
+	switch v := value.(type) {
+	case nil, bool, int64, float64:
+		return v
+	case []byte:
+		switch {
+		case c.IsJSON() && json.Valid(v):
+			return json.RawMessage(v)
+		case c.IsNumeric() && json.Valid(v):
+			return json.Number(v)
+		case c.IsArray():
+			if elements, ok := parseArray(string(v)); ok {
+				element := resultColumn{Type: strings.TrimSuffix(c.Type, "[]")}
+				return element.typeArrayElements(elements)
+			}
+		}
+		return c.exportText(v)
+	default:
+		return c.formatValue(value, false)
+	}
+}
+
+// exportLiteral returns the SQL literal of a value.
+func (c resultColumn) exportLiteral(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		if v {
+			return "TRUE"
+		}
+		return "FALSE"
+	case int64, float64:
+		return fmt.Sprint(v)
+	case []byte:
+		if c.IsNumeric() {
+			return string(v)
+		}
+	}
+	return pq.QuoteLiteral(c.exportText(value))
+}
+
+type csvWriter struct {
+	writer  *csv.Writer
+	columns []resultColumn
+}
+
+func (w *csvWriter) WriteHeader(columns []resultColumn) error {
+	w.columns = columns
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = column.Name
+	}
+	return w.writer.Write(names)
+}
+
+func (w *csvWriter) WriteRow(record resultRow) error {
+	fields := make([]string, len(record))
+	for i, value := range record {
+		fields[i] = w.columns[i].exportText(value)
+	}
+	return w.writer.Write(fields)
+}
+
+func (w *csvWriter) Flush() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// jsonWriter writes the rows as JSON objects, either in an array or one per
+// line (NDJSON).
+type jsonWriter struct {
+	w       io.Writer
+	array   bool
+	columns []resultColumn
+	count   int
+}
+
+func (w *jsonWriter) WriteHeader(columns []resultColumn) error {
+	w.columns = columns
+	if w.array {
+		_, err := io.WriteString(w.w, "[")
+		return err
+	}
+	return nil
+}
+
+// recordJSON returns a row as a JSON object, with the keys in the order of the
+// columns.
+func recordJSON(columns []resultColumn, record resultRow) (string, error) {
+	var object strings.Builder
+	object.WriteString("{")
+	for i, value := range record {
+		if i > 0 {
+			object.WriteString(",")
+		}
+		key, err := json.Marshal(columns[i].Name)
+		if err != nil {
+			return "", err
+		}
+		data, err := json.Marshal(columns[i].exportJSON(value))
+		if err != nil {
+			return "", err
+		}
+		object.Write(key)
+		object.WriteString(":")
+		object.Write(data)
+	}
+	object.WriteString("}")
+	return object.String(), nil
+}
+
+func (w *jsonWriter) WriteRow(record resultRow) error {
+	object, err := recordJSON(w.columns, record)
+	if err != nil {
+		return err
+	}
+
+	separator := "\n"
+	if w.array && w.count > 0 {
+		separator = ",\n"
+	}
+	w.count++
+
+	if w.array {
+		_, err = io.WriteString(w.w, separator+object)
+		return err
+	}
+	_, err = io.WriteString(w.w, object+"\n")
+	return err
+}
+
+func (w *jsonWriter) Flush() error {
+	if w.array {
+		_, err := io.WriteString(w.w, "\n]\n")
+		return err
+	}
+	return nil
+}
+
+type markdownWriter struct {
+	w       io.Writer
+	columns []resultColumn
+}
+
+func (w *markdownWriter) WriteHeader(columns []resultColumn) error {
+	w.columns = columns
+
+	names := make([]string, len(columns))
+	separators := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = markdownEscape(column.Name)
+		separators[i] = "---"
+		if column.IsNumeric() {
+			separators[i] = "---:"
+		}
+	}
+
+	_, err := fmt.Fprintf(w.w, "| %s |\n| %s |\n", strings.Join(names, " | "), strings.Join(separators, " | "))
+	return err
+}
+
+func (w *markdownWriter) WriteRow(record resultRow) error {
+	fields := make([]string, len(record))
+	for i, value := range record {
+		if value == nil {
+			fields[i] = "NULL"
+		} else {
+			fields[i] = markdownEscape(w.columns[i].exportText(value))
+		}
+	}
+
+	_, err := fmt.Fprintf(w.w, "| %s |\n", strings.Join(fields, " | "))
+	return err
+}
+
+func (w *markdownWriter) Flush() error {
+	return nil
+}
+
+func markdownEscape(text string) string {
+	return strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>").Replace(text)
+}
+
+type insertWriter struct {
+	w       io.Writer
+	table   string
+	columns []resultColumn
+	names   string
+}
+
+func (w *insertWriter) WriteHeader(columns []resultColumn) error {
+	w.columns = columns
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = pq.QuoteIdentifier(column.Name)
+	}
+	w.names = strings.Join(names, ", ")
+	return nil
+}
+
+func (w *insertWriter) WriteRow(record resultRow) error {
+	values := make([]string, len(record))
+	for i, value := range record {
+		values[i] = w.columns[i].exportLiteral(value)
+	}
+
+	_, err := fmt.Fprintf(w.w, "INSERT INTO %s (%s) VALUES (%s);\n", w.table, w.names, strings.Join(values, ", "))
+	return err
+}
+
+func (w *insertWriter) Flush() error {
+	return nil
+}
+
+// exportSavepoint is the savepoint in which results are exported in the
+// transaction of the query editor, so that an error does not abort it.
+const exportSavepoint = "csql_export"
+
+// exportInSavepoint exports the rows of a query in the transaction of the
+// query editor, in a savepoint.
+func exportInSavepoint(ctx context.Context, tx *sql.Tx, query string, format ExportFormat, path string, table string, progress func(int64)) (int64, error) {
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT "+exportSavepoint); err != nil {
+		return 0, err
+	}
+
+	count, err := exportQuery(ctx, tx, query, format, path, table, progress)
+
+	// The savepoint is released even when the export was cancelled
+	for _, statement := range []string{"ROLLBACK TO SAVEPOINT ", "RELEASE SAVEPOINT "} {
+		if _, s_err := tx.ExecContext(context.Background(), statement+exportSavepoint); err == nil {
+			err = s_err
+		}
+	}
+	return count, err
+}
+
+// exportQuery executes a query and writes all its rows to a file, calling
+// progress with the number of rows written so far.
+func exportQuery(ctx context.Context, conn queryer, query string, format ExportFormat, path string, table string, progress func(int64)) (count int64, err error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		c_err := file.Close()
+		if c_err != nil && err == nil {
+			err = c_err
+		}
+	}()
+
+	buffer := bufio.NewWriter(file)
+	writer, err := newResultWriter(format, buffer, table)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := conn.QueryContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		r_err := rows.Close()
+		if r_err != nil && err == nil {
+			err = r_err
+		}
+	}()
+
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return 0, err
+	}
+	columns := newResultColumns(columnTypes)
+
+	if err := writer.WriteHeader(columns); err != nil {
+		return 0, err
+	}
+
+	for {
+		records, err := scanRows(rows, len(columns), exportProgressInterval)
+		if err != nil {
+			return count, err
+		}
+		for _, record := range records {
+			if err := writer.WriteRow(record); err != nil {
+				return count, err
+			}
+		}
+		count += int64(len(records))
+		progress(count)
+
+		if len(records) < exportProgressInterval {
+			break
+		}
+	}
+
+	if err := writer.Flush(); err != nil {
+		return count, err
+	}
+
+	return count, buffer.Flush()
+}
+
+// ExportModal asks for the format and the file of an export of the result of
+// a query.
+type ExportModal struct {
+	*PostgreSQLAdapter
+
+	query string
+	// name is used for the default file name and the table of INSERT statements
+	name string
+	// tx is the transaction of the query editor in which the query is
+	// executed again, so that the rows it changed are exported, or nil
+	tx *sql.Tx
+}
+
+func NewExportModal(adapter *PostgreSQLAdapter, query string, name string, tx *sql.Tx) *ExportModal {
+	return &ExportModal{
+		PostgreSQLAdapter: adapter,
+		query:             query,
+		name:              name,
+		tx:                tx,
+	}
+}
+
+func (e *ExportModal) GetTitle() string {
+	return "Export"
+}
+
+func (e *ExportModal) GetContent(s *session.Session) tview.Primitive {
+	var form *tview.Form
+
+	fileName := strings.NewReplacer(`"`, "", "/", "_", `\`, "_").Replace(e.name)
+	path := fileName + exportExtension(ExportCSV)
+
+	pathField := tview.NewInputField().
+		SetLabel("File").
+		SetText(path)
+
+	form = tview.NewForm().
+		AddDropDown("Format", ExportFormats, 0, func(option string, optionIndex int) {
+			// Follow the format in the extension of the default file name
+			current := pathField.GetText()
+			if strings.TrimSuffix(current, filepath.Ext(current)) == fileName {
+				pathField.SetText(fileName + exportExtension(option))
+			}
+		}).
+		AddFormItem(pathField).
+		AddInputField("Table", e.name, 0, nil, nil).
+		AddButton("Export", func() {
+			_, format := form.GetFormItemByLabel("Format").(*tview.DropDown).GetCurrentOption()
+			path := pathField.GetText()
+			table := form.GetFormItemByLabel("Table").(*tview.InputField).GetText()
+			s.CloseModal()
+			e.export(s, format, path, table)
+		}).
+		AddButton("Cancel", func() {
+			s.CloseModal()
+		})
+
+	form.SetFieldBackgroundColor(tcell.ColorGray)
+	form.SetButtonBackgroundColor(tcell.ColorDarkGray)
+
+	return form
+}
+
+func (e *ExportModal) export(s *session.Session, format ExportFormat, path string, table string) {
+	if sqlutil.Classify(e.query) != sqlutil.Read {
+		s.ShowMessage("Only the results of read queries can be exported.", true)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.ShowProgress("Exporting...", cancel)
+	go func() {
+		defer cancel()
+
+		progress := func(count int64) {
+			s.UpdateProgressAsync(fmt.Sprintf("Exporting...\n\n%d rows written", count))
+		}
+
+		start := time.Now()
+		var count int64
+		var err error
+		if e.tx != nil {
+			count, err = exportInSavepoint(ctx, e.tx, e.query, format, path, table, progress)
+		} else {
+			count, err = exportQuery(ctx, e.conn, e.query, format, path, table, progress)
+		}
+		e.audit(s, "export", e.query, start, count, err)
+		s.CloseProgressAsync()
+		if ctx.Err() == context.Canceled {
+			s.ShowMessageAsync("Export cancelled", true)
+			return
+		}
+		if err != nil {
+			s.ShowMessageAsync(fmt.Sprintf("Error: %s", err), true)
+			return
+		}
+
+		s.ShowMessageAsync(fmt.Sprintf("%d rows exported to %s", count, path), true)
+	}()
+}
+
+func (e *ExportModal) GetKeyBindings() (keybindings []*session.KeyBinding) {
+	return
+}
+
+func (e *ExportModal) GetInfo() (info []session.Info) {
+	return
+}
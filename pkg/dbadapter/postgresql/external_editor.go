@@ -0,0 +1,74 @@
+package postgresql
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/rhariady/csql/pkg/session"
+)
+
+// getExternalEditor returns the command line of the editor of the user, from
+// $VISUAL or $EDITOR, which may contain arguments (e.g. "code --wait").
+func getExternalEditor() []string {
+	for _, variable := range []string{"VISUAL", "EDITOR"} {
+		if command := strings.Fields(os.Getenv(variable)); len(command) > 0 {
+			return command
+		}
+	}
+
+	if runtime.GOOS == "windows" {
+		return []string{"notepad"}
+	}
+	return []string{"vi"}
+}
+
+// editExternally edits a text in the editor of the user, in a temporary .sql
+// file, while the application is suspended. It returns the edited text once
+// the editor exits.
+func editExternally(s *session.Session, text string) (string, error) {
+	file, err := os.CreateTemp("", "csql-*.sql")
+	if err != nil {
+		return "", err
+	}
+	path := file.Name()
+	defer os.Remove(path)
+
+	_, err = file.WriteString(text)
+	if c_err := file.Close(); err == nil {
+		err = c_err
+	}
+	if err != nil {
+		return "", err
+	}
+
+	command := getExternalEditor()
+	var runErr error
+	suspended := s.App.Suspend(func() {
+		cmd := exec.Command(command[0], append(command[1:], path)...)
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		runErr = cmd.Run()
+	})
+	if !suspended {
+		return "", errors.New("the application could not be suspended")
+	}
+	if runErr != nil {
+		return "", runErr
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	// Most editors end the file with a newline
+	edited := string(data)
+	if !strings.HasSuffix(text, "\n") {
+		edited = strings.TrimSuffix(strings.TrimSuffix(edited, "\n"), "\r")
+	}
+	return edited, nil
+}
@@ -0,0 +1,98 @@
+package postgresql
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/lib/pq"
+	"github.com/rivo/tview"
+
+	"github.com/rhariady/csql/pkg/session"
+)
+
+// String describes the key, e.g. "public.orders (customer_id) → public.customers (id)".
+func (k foreignKey) String() string {
+	return fmt.Sprintf("%s.%s (%s) → %s.%s (%s)",
+		k.Schema, k.Table, strings.Join(k.Columns, ", "),
+		k.RefSchema, k.RefTable, strings.Join(k.RefColumns, ", "))
+}
+
+// matchCondition returns the WHERE condition selecting the rows whose columns
+// to are equal to the columns from of a row of the grid. The columns from must
+// be shown and not NULL.
+func matchCondition(columns []resultColumn, record resultRow, from []string, to []string) (string, error) {
+	var conditions []string
+	for i, name := range from {
+		idx := slices.IndexFunc(columns, func(column resultColumn) bool { return column.Name == name })
+		if idx < 0 {
+			return "", fmt.Errorf("the column %s must be shown", name)
+		}
+		if record[idx] == nil {
+			return "", fmt.Errorf("the column %s is NULL", name)
+		}
+		conditions = append(conditions, fmt.Sprintf("%s = %s", pq.QuoteIdentifier(to[i]), columns[idx].exportLiteral(record[idx])))
+	}
+	if len(conditions) == 0 {
+		return "", errors.New("the foreign key has no columns")
+	}
+	return strings.Join(conditions, " AND "), nil
+}
+
+// ForeignKeyList lets the user pick one of several foreign keys.
+type ForeignKeyList struct {
+	*PostgreSQLAdapter
+	title    string
+	keys     []foreignKey
+	selected func(key foreignKey)
+}
+
+func NewForeignKeyList(adapter *PostgreSQLAdapter, title string, keys []foreignKey, selected func(key foreignKey)) *ForeignKeyList {
+	return &ForeignKeyList{
+		PostgreSQLAdapter: adapter,
+		title:             title,
+		keys:              keys,
+		selected:          selected,
+	}
+}
+
+func (l *ForeignKeyList) GetTitle() string {
+	return l.title
+}
+
+func (l *ForeignKeyList) GetContent(s *session.Session) tview.Primitive {
+	keyTable := tview.NewTable().
+		SetBorders(false).
+		SetSelectable(true, false)
+
+	keyTable.SetCell(0, 0, tview.NewTableCell("Constraint").SetSelectable(false).SetAttributes(tcell.AttrBold))
+	keyTable.SetCell(0, 1, tview.NewTableCell("Columns").SetSelectable(false).SetAttributes(tcell.AttrBold).SetExpansion(1))
+	for i, key := range l.keys {
+		keyTable.SetCell(i+1, 0, tview.NewTableCell(tview.Escape(key.Name)))
+		keyTable.SetCell(i+1, 1, tview.NewTableCell(tview.Escape(key.String())))
+	}
+	keyTable.Select(1, 0)
+
+	keyTable.SetSelectedFunc(func(row int, column int) {
+		if row <= 0 || row > len(l.keys) {
+			return
+		}
+		s.CloseModal()
+		l.selected(l.keys[row-1])
+	})
+
+	return keyTable
+}
+
+func (l *ForeignKeyList) GetKeyBindings() (keybindings []*session.KeyBinding) {
+	keybindings = []*session.KeyBinding{
+		session.NewKeyBinding("<enter>", "Open rows"),
+	}
+	return
+}
+
+func (l *ForeignKeyList) GetInfo() (info []session.Info) {
+	return
+}
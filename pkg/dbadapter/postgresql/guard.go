@@ -0,0 +1,102 @@
+package postgresql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/rhariady/csql/pkg/session"
+	"github.com/rhariady/csql/pkg/sqlutil"
+)
+
+// confirmStatement calls run once the statement passed the guardrails of the
+// instance environment. On production instances, write and DDL statements
+// must be confirmed, and DROP/TRUNCATE statements require typing the name of
+// the targeted objects.
+func (a *PostgreSQLAdapter) confirmStatement(s *session.Session, statement string, run func()) {
+	if !a.instance.IsProduction() {
+		run()
+		return
+	}
+
+	if command, objects, ok := sqlutil.DestructiveTarget(statement); ok {
+		confirmModal := NewConfirmDestructiveModal(a, command, objects, run)
+		s.ShowModal(confirmModal)
+		return
+	}
+
+	kind := sqlutil.Classify(statement)
+	if kind == sqlutil.Write || kind == sqlutil.DDL {
+		message := fmt.Sprintf("This %s statement will be executed on the production instance %s.\n\nDo you want to continue?", kind, a.instance.Name)
+		s.ShowAlert(message, func(s *session.Session) {
+			run()
+		}, func(s *session.Session) {})
+		return
+	}
+
+	run()
+}
+
+type ConfirmDestructiveModal struct {
+	*PostgreSQLAdapter
+	command string
+	objects string
+	run     func()
+}
+
+func NewConfirmDestructiveModal(adapter *PostgreSQLAdapter, command string, objects string, run func()) *ConfirmDestructiveModal {
+	return &ConfirmDestructiveModal{
+		PostgreSQLAdapter: adapter,
+		command:           command,
+		objects:           objects,
+		run:               run,
+	}
+}
+
+func (c *ConfirmDestructiveModal) GetTitle() string {
+	return fmt.Sprintf("Confirm %s", c.command)
+}
+
+func (c *ConfirmDestructiveModal) GetContent(s *session.Session) tview.Primitive {
+	var form *tview.Form
+
+	message := tview.NewTextView().
+		SetWrap(true).
+		SetText(fmt.Sprintf("You are about to run %s on the production instance %s.\n\nType the object name to confirm:\n%s", c.command, c.instance.Name, c.objects))
+
+	form = tview.NewForm().
+		AddInputField("Object name", "", 0, nil, nil).
+		AddButton("Confirm", func() {
+			objects := form.GetFormItemByLabel("Object name").(*tview.InputField).GetText()
+			if strings.TrimSpace(objects) != c.objects {
+				s.ShowMessage("The object name does not match, the statement was not executed.", true)
+				return
+			}
+			s.CloseModal()
+			c.run()
+		}).
+		AddButton("Cancel", func() {
+			s.CloseModal()
+		})
+
+	form.SetFieldBackgroundColor(tcell.ColorGray)
+	form.SetLabelColor(tcell.ColorRed)
+	form.SetButtonBackgroundColor(tcell.ColorDarkGray)
+
+	layout := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(message, 0, 1, false).
+		AddItem(form, 0, 1, true)
+
+	return layout
+}
+
+func (c *ConfirmDestructiveModal) GetKeyBindings() (keybindings []*session.KeyBinding) {
+	return
+}
+
+func (c *ConfirmDestructiveModal) GetInfo() (info []session.Info) {
+	return
+}
@@ -0,0 +1,104 @@
+package postgresql
+
+import (
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/rivo/uniseg"
+
+	"github.com/rhariady/csql/pkg/sqlutil"
+)
+
+// tokenColors are the colors of the tokens in the query editor. Other tokens
+// keep the color of the text.
+var tokenColors = map[sqlutil.TokenType]tcell.Color{
+	sqlutil.Keyword:          tcell.ColorCornflowerBlue,
+	sqlutil.Identifier:       tcell.ColorLightCyan,
+	sqlutil.QuotedIdentifier: tcell.ColorLightCyan,
+	sqlutil.String:           tcell.ColorDarkSeaGreen,
+	sqlutil.Number:           tcell.ColorLightSalmon,
+	sqlutil.Comment:          tcell.ColorGray,
+}
+
+// sqlTextArea is a text area highlighting the SQL syntax of its text. The
+// text is drawn by the text area, then the cells are colored according to
+// their tokens. It requires the wrapping to be disabled, so that the rows of
+// the text area are the lines of the text.
+type sqlTextArea struct {
+	*tview.TextArea
+	text   string
+	tokens []sqlutil.Token
+}
+
+func newSQLTextArea() *sqlTextArea {
+	textArea := tview.NewTextArea().
+		SetWrap(false)
+	return &sqlTextArea{
+		TextArea: textArea,
+	}
+}
+
+func (t *sqlTextArea) Draw(screen tcell.Screen) {
+	t.TextArea.Draw(screen)
+
+	text := t.GetText()
+	if text != t.text || t.tokens == nil {
+		t.text = text
+		t.tokens = sqlutil.Tokenize(text)
+	}
+
+	x, y, width, height := t.GetInnerRect()
+	rowOffset, columnOffset := t.GetOffset()
+	_, textBackground, _ := t.GetTextStyle().Decompose()
+
+	// Skip the lines above the visible rows
+	pos := 0
+	for row := 0; row < rowOffset; row++ {
+		end := strings.IndexByte(text[pos:], '\n')
+		if end < 0 {
+			return
+		}
+		pos += end + 1
+	}
+
+	token := 0
+	for row := 0; row < height && pos <= len(text); row++ {
+		line := text[pos:]
+		if end := strings.IndexByte(line, '\n'); end >= 0 {
+			line = line[:end]
+		}
+
+		column := 0
+		state := -1
+		rest := line
+		for rest != "" && column-columnOffset < width {
+			var cluster string
+			var clusterWidth int
+			cluster, rest, clusterWidth, state = uniseg.FirstGraphemeClusterInString(rest, state)
+			if cluster == "\t" {
+				clusterWidth = tview.TabSize
+			}
+
+			offset := pos + len(line) - len(rest) - len(cluster)
+			for token < len(t.tokens) && t.tokens[token].End <= offset {
+				token++
+			}
+
+			if column >= columnOffset && clusterWidth > 0 && token < len(t.tokens) {
+				if color, ok := tokenColors[t.tokens[token].Type]; ok {
+					cellX, cellY := x+column-columnOffset, y+row
+					mainc, combc, style, _ := screen.GetContent(cellX, cellY)
+					// Selected text keeps its style
+					if _, background, _ := style.Decompose(); background == textBackground {
+						screen.SetContent(cellX, cellY, mainc, combc, style.Foreground(color))
+					}
+				}
+			}
+
+			column += clusterWidth
+		}
+
+		pos += len(line) + 1
+	}
+}
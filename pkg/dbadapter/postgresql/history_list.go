@@ -0,0 +1,189 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/rhariady/csql/pkg/history"
+	"github.com/rhariady/csql/pkg/session"
+)
+
+const defaultEditorQuery = "SELECT * FROM "
+
+// getEditorQuery returns the query the query editor opens with the first
+// time: the last query executed on the database.
+func (a *PostgreSQLAdapter) getEditorQuery() string {
+	if query, ok := history.Last(a.instance.Name, a.database); ok {
+		return query
+	}
+	return defaultEditorQuery
+}
+
+// recordHistory adds a query executed in the query editor to the history.
+func (a *PostgreSQLAdapter) recordHistory(s *session.Session, query string, start time.Time, err error) {
+	entry := history.Entry{
+		Time:       start,
+		Instance:   a.instance.Name,
+		Database:   a.database,
+		Query:      query,
+		DurationMs: time.Since(start).Milliseconds(),
+		Success:    err == nil,
+	}
+	if errors.Is(err, context.Canceled) {
+		entry.Error = "cancelled"
+	} else if err != nil {
+		entry.Error = err.Error()
+	}
+
+	if h_err := history.Append(entry); h_err != nil {
+		s.ShowMessageAsync(fmt.Sprintf("Error writing query history:\n%s", h_err), true)
+	}
+}
+
+// HistoryList shows the queries executed in the query editor, newest first,
+// with a preview of the selected query.
+type HistoryList struct {
+	*PostgreSQLAdapter
+	// allInstances shows the queries of every instance instead of the
+	// current one only
+	allInstances bool
+}
+
+func NewHistoryList(adapter *PostgreSQLAdapter) *HistoryList {
+	return &HistoryList{
+		PostgreSQLAdapter: adapter,
+	}
+}
+
+func (h *HistoryList) GetTitle() string {
+	return "Query History"
+}
+
+func (h *HistoryList) GetContent(s *session.Session) tview.Primitive {
+	historyTable := tview.NewTable().
+		SetBorders(false).
+		SetSelectable(true, false)
+
+	filterTable := session.NewFilterTable(historyTable)
+	filterTable.SetHeader(
+		tview.NewTableCell("Time"),
+		tview.NewTableCell("Instance"),
+		tview.NewTableCell("Database"),
+		tview.NewTableCell("Duration").SetAlign(tview.AlignRight),
+		tview.NewTableCell("Status"),
+		tview.NewTableCell("Query").SetExpansion(1),
+	)
+
+	preview := tview.NewTextView().
+		SetWrap(true)
+	preview.SetBorder(true).SetTitle("Preview")
+
+	layout := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(filterTable, 0, 2, true).
+		AddItem(preview, 0, 1, false)
+
+	showPreview := func(row int) {
+		preview.SetText("")
+		if entry, ok := getHistoryEntry(historyTable, row); ok {
+			text := entry.Query
+			if entry.Error != "" {
+				text += "\n\n-- Error: " + entry.Error
+			}
+			preview.SetText(text).ScrollToBeginning()
+		}
+	}
+	historyTable.SetSelectionChangedFunc(func(row, column int) {
+		showPreview(row)
+	})
+
+	loadEntries := func() {
+		entries, err := history.Load()
+		if err != nil {
+			s.ShowMessage(fmt.Sprintf("Error reading query history:\n%s", err), true)
+			return
+		}
+
+		var rows [][]*tview.TableCell
+		for _, entry := range entries {
+			if !h.allInstances && entry.Instance != h.instance.Name {
+				continue
+			}
+
+			status := tview.NewTableCell("ok").SetTextColor(tcell.ColorGreen)
+			if !entry.Success {
+				status = tview.NewTableCell("failed").SetTextColor(tcell.ColorRed)
+			}
+
+			// The whole query on one line, so that the filter matches any part of it
+			query := strings.Join(strings.Fields(entry.Query), " ")
+			rows = append(rows, []*tview.TableCell{
+				tview.NewTableCell(entry.Time.Local().Format("2006-01-02 15:04:05")).SetReference(entry),
+				tview.NewTableCell(tview.Escape(entry.Instance)),
+				tview.NewTableCell(tview.Escape(entry.Database)),
+				tview.NewTableCell(fmt.Sprintf("%d ms", entry.DurationMs)).SetAlign(tview.AlignRight),
+				status,
+				tview.NewTableCell(tview.Escape(query)).SetMaxWidth(80),
+			})
+		}
+
+		filterTable.SetRows(rows)
+		row, _ := historyTable.GetSelection()
+		showPreview(row)
+	}
+	loadEntries()
+
+	historyTable.SetSelectedFunc(func(row int, column int) {
+		if entry, ok := getHistoryEntry(historyTable, row); ok {
+			queryEditor := NewQueryEditor(h.PostgreSQLAdapter, entry.Query)
+			s.SetView(queryEditor)
+		}
+	})
+
+	historyTable.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEsc:
+			queryEditor := NewQueryEditor(h.PostgreSQLAdapter, "")
+			s.SetView(queryEditor)
+			return nil
+		case event.Rune() == 'f':
+			filterTable.Show(s)
+			return nil
+		case event.Rune() == 'a':
+			h.allInstances = !h.allInstances
+			loadEntries()
+			return nil
+		}
+		return h.InputCapture(s, event)
+	})
+
+	return layout
+}
+
+func getHistoryEntry(table *tview.Table, row int) (history.Entry, bool) {
+	if row <= 0 || row >= table.GetRowCount() {
+		return history.Entry{}, false
+	}
+	entry, ok := table.GetCell(row, 0).GetReference().(history.Entry)
+	return entry, ok
+}
+
+func (h *HistoryList) GetKeyBindings() (keybindings []*session.KeyBinding) {
+	keybindings = []*session.KeyBinding{
+		session.NewKeyBinding("<enter>", "Load into the query editor"),
+		session.NewKeyBinding("[f]", "Filter queries"),
+		session.NewKeyBinding("[a]", "Toggle all instances"),
+		session.NewKeyBinding("<esc>", "Go back to query editor"),
+	}
+
+	base_keybinding := h.PostgreSQLAdapter.GetKeyBindings()
+	keybindings = append(keybindings, base_keybinding...)
+
+	return
+}
@@ -0,0 +1,171 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/rivo/tview"
+
+	"github.com/rhariady/csql/pkg/session"
+)
+
+// objectKind is a kind of database objects listed by the object list.
+type objectKind string
+
+const (
+	objectRelations  objectKind = "Relations"
+	objectFunctions  objectKind = "Functions"
+	objectTypes      objectKind = "Types"
+	objectExtensions objectKind = "Extensions"
+)
+
+var objectKinds = []objectKind{objectRelations, objectFunctions, objectTypes, objectExtensions}
+
+// getObjectKind returns the kind of objects listed by the object list, the
+// relations by default.
+func (a *PostgreSQLAdapter) getObjectKind() objectKind {
+	if a.objectKind == "" {
+		return objectRelations
+	}
+	return a.objectKind
+}
+
+// getTypeHeader returns the header of the Type column of the list of objects.
+func (k objectKind) getTypeHeader() string {
+	if k == objectExtensions {
+		return "Version"
+	}
+	return "Type"
+}
+
+// listObjects returns the objects of a kind, except the ones of the system
+// schemas and of the extensions.
+func (a *PostgreSQLAdapter) listObjects(ctx context.Context, kind objectKind) (objects []TableRecord, err error) {
+	var query string
+	switch kind {
+	case objectFunctions:
+		query = `SELECT n.nspname,
+p.proname || '(' || pg_catalog.pg_get_function_identity_arguments(p.oid) || ')',
+CASE p.prokind WHEN 'f' THEN 'function' WHEN 'p' THEN 'procedure' WHEN 'a' THEN 'aggregate' WHEN 'w' THEN 'window' END,
+pg_catalog.pg_get_userbyid(p.proowner),
+p.oid
+FROM pg_catalog.pg_proc p
+    JOIN pg_catalog.pg_namespace n ON n.oid = p.pronamespace
+WHERE n.nspname <> 'pg_catalog'
+    AND n.nspname <> 'information_schema'
+    AND NOT EXISTS (SELECT 1 FROM pg_catalog.pg_depend d
+        WHERE d.classid = 'pg_catalog.pg_proc'::regclass AND d.objid = p.oid AND d.deptype = 'e')
+ORDER BY 1, 2`
+	case objectTypes:
+		query = `SELECT n.nspname, t.typname,
+CASE t.typtype WHEN 'e' THEN 'enum' WHEN 'c' THEN 'composite' WHEN 'd' THEN 'domain' WHEN 'r' THEN 'range'
+    WHEN 'm' THEN 'multirange' WHEN 'b' THEN 'base' ELSE 'pseudo' END,
+pg_catalog.pg_get_userbyid(t.typowner),
+t.oid
+FROM pg_catalog.pg_type t
+    JOIN pg_catalog.pg_namespace n ON n.oid = t.typnamespace
+WHERE n.nspname <> 'pg_catalog'
+    AND n.nspname <> 'information_schema'
+    AND (t.typrelid = 0 OR (SELECT c.relkind = 'c' FROM pg_catalog.pg_class c WHERE c.oid = t.typrelid))
+    AND NOT EXISTS (SELECT 1 FROM pg_catalog.pg_type el WHERE el.oid = t.typelem AND el.typarray = t.oid)
+    AND t.typtype <> 'm'
+    AND NOT EXISTS (SELECT 1 FROM pg_catalog.pg_depend d
+        WHERE d.classid = 'pg_catalog.pg_type'::regclass AND d.objid = t.oid AND d.deptype = 'e')
+ORDER BY 1, 2`
+	case objectExtensions:
+		query = `SELECT n.nspname, e.extname, e.extversion,
+pg_catalog.pg_get_userbyid(e.extowner),
+e.oid
+FROM pg_catalog.pg_extension e
+    JOIN pg_catalog.pg_namespace n ON n.oid = e.extnamespace
+ORDER BY 2`
+	default:
+		return a.listTables()
+	}
+
+	err = a.queryEach(ctx, query, nil, func(rows *sql.Rows) error {
+		var object TableRecord
+		err := rows.Scan(&object.Schema, &object.Name, &object.Type, &object.Owner, &object.Oid)
+		objects = append(objects, object)
+		return err
+	})
+	return objects, err
+}
+
+// refreshMaterializedView refreshes a materialized view, after the
+// confirmation required on production instances, and calls done once
+// refreshed.
+func (a *PostgreSQLAdapter) refreshMaterializedView(s *session.Session, schema string, name string, done func()) {
+	statement := fmt.Sprintf("REFRESH MATERIALIZED VIEW %s", qualifiedName(schema, name))
+	a.confirmStatement(s, statement, func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		s.ShowProgress("Refreshing materialized view...", cancel)
+		go func() {
+			defer cancel()
+
+			start := time.Now()
+			_, err := a.conn.ExecContext(ctx, statement)
+			a.audit(s, "refresh", statement, start, 0, err)
+			s.CloseProgressAsync()
+			if ctx.Err() == context.Canceled {
+				s.ShowMessageAsync("Refresh cancelled", true)
+				return
+			}
+			if err != nil {
+				s.ShowMessageAsync(fmt.Sprintf("Error: %s", err), true)
+				return
+			}
+
+			s.App.QueueUpdateDraw(func() {
+				s.ShowNotice(fmt.Sprintf("%s.%s refreshed", schema, name))
+				done()
+			})
+		}()
+	})
+}
+
+// ObjectKindModal switches the kind of objects of the object list.
+type ObjectKindModal struct {
+	*PostgreSQLAdapter
+}
+
+func NewObjectKindModal(adapter *PostgreSQLAdapter) *ObjectKindModal {
+	return &ObjectKindModal{
+		PostgreSQLAdapter: adapter,
+	}
+}
+
+func (m *ObjectKindModal) GetTitle() string {
+	return "Select the objects to browse"
+}
+
+func (m *ObjectKindModal) GetContent(s *session.Session) tview.Primitive {
+	kindTable := tview.NewTable().
+		SetBorders(false).
+		SetSelectable(true, false)
+
+	for row, kind := range objectKinds {
+		kindTable.SetCell(row, 0, tview.NewTableCell(string(kind)).SetExpansion(1))
+		if kind == m.getObjectKind() {
+			kindTable.Select(row, 0)
+		}
+	}
+
+	kindTable.SetSelectedFunc(func(row int, column int) {
+		s.CloseModal()
+		m.objectKind = objectKinds[row]
+		s.SetView(NewTableList(m.PostgreSQLAdapter))
+	})
+
+	return kindTable
+}
+
+func (m *ObjectKindModal) GetKeyBindings() (keybindings []*session.KeyBinding) {
+	return
+}
+
+func (m *ObjectKindModal) GetInfo() (info []session.Info) {
+	return
+}
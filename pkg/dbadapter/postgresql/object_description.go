@@ -0,0 +1,573 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/lib/pq"
+	"github.com/rivo/tview"
+
+	"github.com/rhariady/csql/pkg/session"
+)
+
+// describedObject is the description of a database object, shown in sections,
+// with the statements creating it.
+type describedObject interface {
+	getSections() []describeSection
+	DDL() string
+}
+
+// describeSection is a section of the description of an object, shown as a
+// table, or as text when it has no header.
+type describeSection struct {
+	name   string
+	header []string
+	rows   [][]string
+	text   string
+}
+
+func newOverviewSection() describeSection {
+	return describeSection{name: "Overview", header: []string{"Property", "Value"}}
+}
+
+// addProperty adds a row to an overview section, unless the value is empty.
+func (s *describeSection) addProperty(name string, value string) {
+	if value != "" {
+		s.rows = append(s.rows, []string{name, value})
+	}
+}
+
+// describeObject reads the description of an object of the list of objects.
+func (a *PostgreSQLAdapter) describeObject(ctx context.Context, kind objectKind, record TableRecord) (describedObject, error) {
+	var object describedObject
+	var err error
+	switch kind {
+	case objectFunctions:
+		object, err = a.describeFunction(ctx, record.Oid)
+	case objectTypes:
+		object, err = a.describeType(ctx, record.Oid)
+	case objectExtensions:
+		object, err = a.describeExtension(ctx, record.Oid)
+	default:
+		object, err = a.describeTable(ctx, record.Schema, record.Name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+// functionDescription is the definition of a function, a procedure or an
+// aggregate.
+type functionDescription struct {
+	Schema            string
+	Name              string
+	Kind              string
+	Owner             string
+	Language          string
+	Arguments         string
+	IdentityArguments string
+	Result            string
+	Volatility        string
+	Parallel          string
+	SecurityDefiner   bool
+	Strict            bool
+	Comment           string
+	// Definition is the CREATE statement of the function, not available for
+	// aggregates
+	Definition string
+}
+
+func (a *PostgreSQLAdapter) describeFunction(ctx context.Context, oid uint32) (*functionDescription, error) {
+	f := &functionDescription{}
+	err := a.queryEach(ctx, `SELECT n.nspname, p.proname,
+CASE p.prokind WHEN 'f' THEN 'function' WHEN 'p' THEN 'procedure' WHEN 'a' THEN 'aggregate' WHEN 'w' THEN 'window' END,
+pg_catalog.pg_get_userbyid(p.proowner), l.lanname,
+pg_catalog.pg_get_function_arguments(p.oid), pg_catalog.pg_get_function_identity_arguments(p.oid),
+COALESCE(pg_catalog.pg_get_function_result(p.oid), ''),
+CASE p.provolatile WHEN 'i' THEN 'immutable' WHEN 's' THEN 'stable' ELSE 'volatile' END,
+CASE p.proparallel WHEN 's' THEN 'safe' WHEN 'r' THEN 'restricted' ELSE 'unsafe' END,
+p.prosecdef, p.proisstrict,
+COALESCE(pg_catalog.obj_description(p.oid, 'pg_proc'), ''),
+CASE WHEN p.prokind <> 'a' THEN pg_catalog.pg_get_functiondef(p.oid) ELSE '' END
+FROM pg_catalog.pg_proc p
+    JOIN pg_catalog.pg_namespace n ON n.oid = p.pronamespace
+    JOIN pg_catalog.pg_language l ON l.oid = p.prolang
+WHERE p.oid = $1`, []any{oid}, func(rows *sql.Rows) error {
+		return rows.Scan(&f.Schema, &f.Name, &f.Kind, &f.Owner, &f.Language, &f.Arguments, &f.IdentityArguments, &f.Result,
+			&f.Volatility, &f.Parallel, &f.SecurityDefiner, &f.Strict, &f.Comment, &f.Definition)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if f.Name == "" {
+		return nil, fmt.Errorf("the function %d does not exist anymore", oid)
+	}
+	return f, nil
+}
+
+func (f *functionDescription) DDL() string {
+	if f.Definition == "" {
+		return fmt.Sprintf("-- The definition of %s functions is not available\n", f.Kind)
+	}
+
+	ddl := strings.TrimSpace(f.Definition) + ";\n"
+	if f.Comment != "" {
+		object := "FUNCTION"
+		if f.Kind == "procedure" {
+			object = "PROCEDURE"
+		}
+		ddl += fmt.Sprintf("\nCOMMENT ON %s %s(%s) IS %s;\n",
+			object, qualifiedName(f.Schema, f.Name), f.IdentityArguments, pq.QuoteLiteral(f.Comment))
+	}
+	return ddl
+}
+
+func (f *functionDescription) getSections() []describeSection {
+	overview := newOverviewSection()
+	overview.addProperty("Type", f.Kind)
+	overview.addProperty("Owner", f.Owner)
+	overview.addProperty("Language", f.Language)
+	overview.addProperty("Arguments", f.Arguments)
+	overview.addProperty("Returns", f.Result)
+	overview.addProperty("Volatility", f.Volatility)
+	overview.addProperty("Parallel", f.Parallel)
+	overview.addProperty("Strict", strconv.FormatBool(f.Strict))
+	overview.addProperty("Security definer", strconv.FormatBool(f.SecurityDefiner))
+	overview.addProperty("Comment", f.Comment)
+
+	return []describeSection{overview, {name: "Source", text: f.DDL()}}
+}
+
+// typeDescription is the definition of a user-defined type: an enum, a
+// composite type, a domain or a range.
+type typeDescription struct {
+	Schema  string
+	Name    string
+	Kind    string
+	Owner   string
+	Comment string
+	// BaseType, Default and NotNull define a domain
+	BaseType string
+	Default  string
+	NotNull  bool
+	// Subtype is the type of the bounds of a range
+	Subtype     string
+	Labels      []string
+	Attributes  [][]string
+	Constraints []describedConstraint
+}
+
+func (a *PostgreSQLAdapter) describeType(ctx context.Context, oid uint32) (*typeDescription, error) {
+	t := &typeDescription{}
+	args := []any{oid}
+
+	err := a.queryEach(ctx, `SELECT n.nspname, t.typname,
+CASE t.typtype WHEN 'e' THEN 'enum' WHEN 'c' THEN 'composite' WHEN 'd' THEN 'domain' WHEN 'r' THEN 'range'
+    WHEN 'm' THEN 'multirange' WHEN 'b' THEN 'base' ELSE 'pseudo' END,
+pg_catalog.pg_get_userbyid(t.typowner),
+COALESCE(pg_catalog.obj_description(t.oid, 'pg_type'), ''),
+CASE WHEN t.typtype = 'd' THEN pg_catalog.format_type(t.typbasetype, t.typtypmod) ELSE '' END,
+COALESCE(t.typdefault, ''), t.typnotnull,
+COALESCE((SELECT pg_catalog.format_type(r.rngsubtype, NULL) FROM pg_catalog.pg_range r WHERE r.rngtypid = t.oid), '')
+FROM pg_catalog.pg_type t
+    JOIN pg_catalog.pg_namespace n ON n.oid = t.typnamespace
+WHERE t.oid = $1`, args, func(rows *sql.Rows) error {
+		return rows.Scan(&t.Schema, &t.Name, &t.Kind, &t.Owner, &t.Comment, &t.BaseType, &t.Default, &t.NotNull, &t.Subtype)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if t.Name == "" {
+		return nil, fmt.Errorf("the type %d does not exist anymore", oid)
+	}
+
+	err = a.queryEach(ctx, `SELECT e.enumlabel
+FROM pg_catalog.pg_enum e
+WHERE e.enumtypid = $1
+ORDER BY e.enumsortorder`, args, func(rows *sql.Rows) error {
+		var label string
+		err := rows.Scan(&label)
+		t.Labels = append(t.Labels, label)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.queryEach(ctx, `SELECT a.attname, pg_catalog.format_type(a.atttypid, a.atttypmod)
+FROM pg_catalog.pg_type t
+    JOIN pg_catalog.pg_attribute a ON a.attrelid = t.typrelid
+WHERE t.oid = $1
+    AND a.attnum > 0
+    AND NOT a.attisdropped
+ORDER BY a.attnum`, args, func(rows *sql.Rows) error {
+		var name, attributeType string
+		err := rows.Scan(&name, &attributeType)
+		t.Attributes = append(t.Attributes, []string{name, attributeType})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.queryEach(ctx, `SELECT c.conname, pg_catalog.pg_get_constraintdef(c.oid, true)
+FROM pg_catalog.pg_constraint c
+WHERE c.contypid = $1
+    AND c.contype = 'c'
+ORDER BY c.conname`, args, func(rows *sql.Rows) error {
+		constraint := describedConstraint{Type: "check", Local: true}
+		err := rows.Scan(&constraint.Name, &constraint.Definition)
+		t.Constraints = append(t.Constraints, constraint)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (t *typeDescription) DDL() string {
+	name := qualifiedName(t.Schema, t.Name)
+
+	var ddl string
+	switch t.Kind {
+	case "enum":
+		var labels []string
+		for _, label := range t.Labels {
+			labels = append(labels, pq.QuoteLiteral(label))
+		}
+		ddl = fmt.Sprintf("CREATE TYPE %s AS ENUM (\n    %s\n);\n", name, strings.Join(labels, ",\n    "))
+	case "composite":
+		var attributes []string
+		for _, attribute := range t.Attributes {
+			attributes = append(attributes, fmt.Sprintf("%s %s", pq.QuoteIdentifier(attribute[0]), attribute[1]))
+		}
+		ddl = fmt.Sprintf("CREATE TYPE %s AS (\n    %s\n);\n", name, strings.Join(attributes, ",\n    "))
+	case "domain":
+		ddl = fmt.Sprintf("CREATE DOMAIN %s AS %s", name, t.BaseType)
+		if t.Default != "" {
+			ddl += " DEFAULT " + t.Default
+		}
+		if t.NotNull {
+			ddl += " NOT NULL"
+		}
+		for _, constraint := range t.Constraints {
+			ddl += fmt.Sprintf("\n    CONSTRAINT %s %s", pq.QuoteIdentifier(constraint.Name), constraint.Definition)
+		}
+		ddl += ";\n"
+	case "range":
+		ddl = fmt.Sprintf("CREATE TYPE %s AS RANGE (SUBTYPE = %s);\n", name, t.Subtype)
+	default:
+		return fmt.Sprintf("-- The definition of %s types is not available\n", t.Kind)
+	}
+
+	if t.Comment != "" {
+		object := "TYPE"
+		if t.Kind == "domain" {
+			object = "DOMAIN"
+		}
+		ddl += fmt.Sprintf("\nCOMMENT ON %s %s IS %s;\n", object, name, pq.QuoteLiteral(t.Comment))
+	}
+	return ddl
+}
+
+func (t *typeDescription) getSections() []describeSection {
+	overview := newOverviewSection()
+	overview.addProperty("Type", t.Kind)
+	overview.addProperty("Owner", t.Owner)
+	overview.addProperty("Base type", t.BaseType)
+	overview.addProperty("Default", t.Default)
+	if t.NotNull {
+		overview.addProperty("Nullable", "not null")
+	}
+	overview.addProperty("Subtype", t.Subtype)
+	overview.addProperty("Comment", t.Comment)
+
+	labels := describeSection{name: "Labels", header: []string{"Label"}}
+	for _, label := range t.Labels {
+		labels.rows = append(labels.rows, []string{label})
+	}
+
+	attributes := describeSection{name: "Attributes", header: []string{"Attribute", "Type"}, rows: t.Attributes}
+
+	constraints := describeSection{name: "Constraints", header: []string{"Constraint", "Type", "Definition"}}
+	for _, constraint := range t.Constraints {
+		constraints.rows = append(constraints.rows, []string{constraint.Name, constraint.Type, constraint.Definition})
+	}
+
+	sections := []describeSection{overview}
+	for _, section := range []describeSection{labels, attributes, constraints} {
+		if len(section.rows) > 0 {
+			sections = append(sections, section)
+		}
+	}
+	return append(sections, describeSection{name: "DDL", text: t.DDL()})
+}
+
+// extensionDescription is an installed extension, with the objects it
+// created.
+type extensionDescription struct {
+	Name    string
+	Schema  string
+	Version string
+	// DefaultVersion is the version installed by CREATE EXTENSION, to which
+	// the extension can be updated
+	DefaultVersion string
+	Owner          string
+	Comment        string
+	Relocatable    bool
+	Objects        []string
+}
+
+func (a *PostgreSQLAdapter) describeExtension(ctx context.Context, oid uint32) (*extensionDescription, error) {
+	e := &extensionDescription{}
+	args := []any{oid}
+
+	err := a.queryEach(ctx, `SELECT e.extname, n.nspname, e.extversion,
+COALESCE(av.default_version, ''),
+pg_catalog.pg_get_userbyid(e.extowner),
+COALESCE(pg_catalog.obj_description(e.oid, 'pg_extension'), ''),
+e.extrelocatable
+FROM pg_catalog.pg_extension e
+    JOIN pg_catalog.pg_namespace n ON n.oid = e.extnamespace
+    LEFT JOIN pg_catalog.pg_available_extensions av ON av.name = e.extname
+WHERE e.oid = $1`, args, func(rows *sql.Rows) error {
+		return rows.Scan(&e.Name, &e.Schema, &e.Version, &e.DefaultVersion, &e.Owner, &e.Comment, &e.Relocatable)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if e.Name == "" {
+		return nil, fmt.Errorf("the extension %d does not exist anymore", oid)
+	}
+
+	err = a.queryEach(ctx, `SELECT pg_catalog.pg_describe_object(d.classid, d.objid, 0)
+FROM pg_catalog.pg_depend d
+WHERE d.refclassid = 'pg_catalog.pg_extension'::regclass
+    AND d.refobjid = $1
+    AND d.deptype = 'e'
+ORDER BY 1`, args, func(rows *sql.Rows) error {
+		var object string
+		err := rows.Scan(&object)
+		e.Objects = append(e.Objects, object)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+func (e *extensionDescription) DDL() string {
+	return fmt.Sprintf("CREATE EXTENSION IF NOT EXISTS %s WITH SCHEMA %s VERSION %s;\n",
+		pq.QuoteIdentifier(e.Name), pq.QuoteIdentifier(e.Schema), pq.QuoteLiteral(e.Version))
+}
+
+func (e *extensionDescription) getSections() []describeSection {
+	overview := newOverviewSection()
+	overview.addProperty("Version", e.Version)
+	if e.DefaultVersion != "" && e.DefaultVersion != e.Version {
+		overview.addProperty("Available version", fmt.Sprintf("%s (ALTER EXTENSION %s UPDATE)", e.DefaultVersion, pq.QuoteIdentifier(e.Name)))
+	}
+	overview.addProperty("Schema", e.Schema)
+	overview.addProperty("Owner", e.Owner)
+	overview.addProperty("Relocatable", strconv.FormatBool(e.Relocatable))
+	overview.addProperty("Comment", e.Comment)
+
+	objects := describeSection{name: "Objects", header: []string{"Object"}}
+	for _, object := range e.Objects {
+		objects.rows = append(objects.rows, []string{object})
+	}
+
+	return []describeSection{overview, objects, {name: "DDL", text: e.DDL()}}
+}
+
+// ObjectDescription shows the description of an object of the list of
+// objects by sections, e.g. the columns, indexes, constraints, triggers,
+// policies, partitions and DDL of a table.
+type ObjectDescription struct {
+	*PostgreSQLAdapter
+
+	kind   objectKind
+	record TableRecord
+	object describedObject
+
+	sectionList  *tview.List
+	sectionPages *tview.Pages
+}
+
+func NewObjectDescription(adapter *PostgreSQLAdapter, kind objectKind, record TableRecord) *ObjectDescription {
+	return &ObjectDescription{
+		PostgreSQLAdapter: adapter,
+		kind:              kind,
+		record:            record,
+	}
+}
+
+func (o *ObjectDescription) GetTitle() string {
+	if o.kind == objectExtensions {
+		return fmt.Sprintf("Describe extension %s", o.record.Name)
+	}
+	return fmt.Sprintf("Describe %s %s.%s", o.record.Type, o.record.Schema, o.record.Name)
+}
+
+func (o *ObjectDescription) GetContent(s *session.Session) tview.Primitive {
+	o.sectionList = tview.NewList().
+		ShowSecondaryText(false).
+		SetHighlightFullLine(true)
+	o.sectionList.SetBorder(true)
+
+	o.sectionPages = tview.NewPages()
+
+	layout := tview.NewFlex().
+		AddItem(o.sectionList, 20, 0, true).
+		AddItem(o.sectionPages, 0, 1, false)
+
+	o.sectionList.SetChangedFunc(func(index int, mainText string, secondaryText string, shortcut rune) {
+		o.sectionPages.SwitchToPage(secondaryText)
+	})
+	o.sectionList.SetSelectedFunc(func(index int, mainText string, secondaryText string, shortcut rune) {
+		s.App.SetFocus(o.sectionPages)
+	})
+	o.sectionList.SetDoneFunc(func() {
+		s.SetView(NewTableList(o.PostgreSQLAdapter))
+	})
+
+	layout.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyTab:
+			if o.sectionList.HasFocus() {
+				s.App.SetFocus(o.sectionPages)
+			} else {
+				s.App.SetFocus(o.sectionList)
+			}
+			return nil
+		case event.Key() == tcell.KeyEsc && !o.sectionList.HasFocus():
+			s.App.SetFocus(o.sectionList)
+			return nil
+		case event.Rune() == 'c' && o.object != nil:
+			copyText(s, o.object.DDL(), "DDL copied to the clipboard")
+			return nil
+		case event.Rune() == 'v' && o.object != nil:
+			s.SetView(NewQueryEditor(o.PostgreSQLAdapter, o.object.DDL()))
+			return nil
+		case event.Rune() == 'r' && o.kind == objectRelations:
+			s.SetView(NewTableQuery(o.PostgreSQLAdapter, o.record.Schema, o.record.Name))
+			return nil
+		case event.Rune() == 'R' && o.record.Type == "materialized view":
+			o.refreshMaterializedView(s, o.record.Schema, o.record.Name, func() {
+				o.load(s)
+			})
+			return nil
+		}
+		if o.sectionList.HasFocus() {
+			return o.InputCapture(s, event)
+		}
+		return event
+	})
+
+	o.load(s)
+
+	return layout
+}
+
+// load reads the description of the object, and shows its sections.
+func (o *ObjectDescription) load(s *session.Session) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.ShowProgress("Loading description...", cancel)
+	go func() {
+		defer cancel()
+
+		object, err := o.describeObject(ctx, o.kind, o.record)
+		s.CloseProgressAsync()
+		if err != nil {
+			s.ShowMessageAsync(fmt.Sprintf("Error: %s", err), true)
+			return
+		}
+
+		s.App.QueueUpdateDraw(func() {
+			o.object = object
+			current := o.sectionList.GetCurrentItem()
+			o.sectionList.Clear()
+			for _, name := range o.sectionPages.GetPageNames(false) {
+				o.sectionPages.RemovePage(name)
+			}
+
+			for _, section := range object.getSections() {
+				label := section.name
+				if section.header != nil && section.name != "Overview" {
+					label = fmt.Sprintf("%s (%d)", section.name, len(section.rows))
+				}
+				o.sectionPages.AddPage(section.name, newSectionContent(section), true, false)
+				o.sectionList.AddItem(label, section.name, 0, nil)
+			}
+			o.sectionList.SetCurrentItem(current)
+			_, name := o.sectionList.GetItemText(o.sectionList.GetCurrentItem())
+			o.sectionPages.SwitchToPage(name)
+		})
+	}()
+}
+
+// newSectionContent returns the table or the text of a section.
+func newSectionContent(section describeSection) tview.Primitive {
+	if section.header == nil {
+		text := tview.NewTextView().
+			SetText(section.text).
+			SetWrap(false)
+		text.SetBorder(true).SetTitle(section.name)
+		return text
+	}
+
+	table := tview.NewTable().
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	table.SetBorder(true).SetTitle(section.name)
+
+	for i, name := range section.header {
+		table.SetCell(0, i, tview.NewTableCell(name).SetSelectable(false).SetAttributes(tcell.AttrBold))
+	}
+	for i, row := range section.rows {
+		for j, value := range row {
+			cell := tview.NewTableCell(tview.Escape(lineBreakReplacer.Replace(value)))
+			if j == len(row)-1 {
+				cell.SetExpansion(1)
+			}
+			table.SetCell(i+1, j, cell)
+		}
+	}
+	return table
+}
+
+func (o *ObjectDescription) GetKeyBindings() (keybindings []*session.KeyBinding) {
+	keybindings = []*session.KeyBinding{
+		session.NewKeyBinding("<escape>", "Go back to object list"),
+		session.NewKeyBinding("<tab>", "Switch focus"),
+	}
+	if o.kind == objectRelations {
+		keybindings = append(keybindings, session.NewKeyBinding("r", "Query rows"))
+	}
+	if o.record.Type == "materialized view" {
+		keybindings = append(keybindings, session.NewKeyBinding("R", "Refresh materialized view"))
+	}
+	keybindings = append(keybindings,
+		session.NewKeyBinding("c", "Copy DDL"),
+		session.NewKeyBinding("v", "Open DDL in editor"),
+	)
+
+	base_keybinding := o.PostgreSQLAdapter.GetKeyBindings()
+	keybindings = append(keybindings, base_keybinding...)
+
+	return
+}
+
+func (o *ObjectDescription) GetInfo() (info []session.Info) {
+	info = o.PostgreSQLAdapter.GetInfo()
+	return
+}
@@ -4,19 +4,49 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	_ "strings"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 
 	"github.com/rhariady/csql/pkg/session"
+	"github.com/rhariady/csql/pkg/snippet"
+	"github.com/rhariady/csql/pkg/sqlutil"
 )
 
 type QueryEditor struct {
 	*PostgreSQLAdapter
-	query string
+	// query is opened in a new buffer, when not empty
+	query     string
+	session   *session.Session
+	workspace *editorWorkspace
+	// buffer is the buffer shown in the editor, and grid is the rows of its
+	// result shown, if any
+	buffer      *editorBuffer
+	grid        *ResultGrid
+	bufferTabs  *tview.TextView
+	queryInput  *tview.TextArea
+	resultTable *tview.Table
+	resultTabs  *tview.TextView
+	resultPane  *tview.Flex
+	completion  *completionPopup
+	// transaction is the explicit transaction of the transaction mode, nil
+	// in auto-commit mode
+	transaction *editorTransaction
+	// saveTimer saves the buffers once the text has not changed for
+	// workspaceSaveDelay
+	saveTimer *time.Timer
 }
 
+// workspaceSaveDelay is the time without typing after which the buffers are
+// saved, so that the text is not lost when csql is quit.
+const workspaceSaveDelay = time.Second
+
+// NewQueryEditor creates a query editor showing the buffers of the database.
+// A query which is not empty is opened in a new buffer.
 func NewQueryEditor(adapter *PostgreSQLAdapter, query string) *QueryEditor {
 	return &QueryEditor{
 		PostgreSQLAdapter: adapter,
@@ -29,63 +59,186 @@ func (tq *QueryEditor) GetTitle() string {
 }
 
 func (tq *QueryEditor) GetContent(session *session.Session) tview.Primitive {
-	queryInput := tview.NewTextArea()
-	queryInput.SetText(tq.query, true)
+	tq.session = session
+	tq.workspace = tq.getWorkspace(session)
+	if tq.query != "" {
+		tq.workspace.Add("", tq.query)
+	}
+
+	bufferTabs := tview.NewTextView().
+		SetDynamicColors(true).
+		SetRegions(true).
+		SetWrap(false)
+	tq.bufferTabs = bufferTabs
+
+	editor := newSQLTextArea()
+	queryInput := editor.TextArea
+	tq.queryInput = queryInput
 
 	queryResultTable := tview.NewTable().
 		SetBorders(true).
-		SetSelectable(false, false).
+		SetSelectable(true, true).
 		SetFixed(1, 0)
+	tq.resultTable = queryResultTable
 
-	queryInput.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		if event.Key() == tcell.KeyCtrlX {
-			tq.query = queryInput.GetText()
-			session.ShowMessage("Executing query...", false)
-			go func() {
-				rows, columns, err := executeQuery(tq.conn, tq.query)
-				session.CloseMessageAsync()
-				if err != nil {
-					session.ShowMessageAsync(fmt.Sprintf("Error: %s", err), true)
-					return
-				}
+	resultTabs := tview.NewTextView().
+		SetDynamicColors(true).
+		SetRegions(true).
+		SetWrap(false)
+	tq.resultTabs = resultTabs
+
+	resultPane := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(resultTabs, 0, 0, false).
+		AddItem(queryResultTable, 0, 1, false)
+	tq.resultPane = resultPane
 
-				session.App.QueueUpdateDraw(func() {
-					queryResultTable.Clear()
-					for idx, column := range columns {
-						queryResultTable.SetCell(0, idx, tview.NewTableCell(column).SetSelectable(false))
-					}
+	completion := newCompletionPopup(tq.PostgreSQLAdapter, session, queryInput)
+	tq.completion = completion
 
-					for i, row := range rows {
-						for j, column := range columns {
-							queryResultTable.SetCell(i+1, j, tview.NewTableCell(row[column]))
-						}
-					}
-					queryResultTable.ScrollToBeginning()
-				})
-			}()
+	queryInput.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if completion.HandleKey(event) {
+			return nil
+		}
+		if event.Key() == tcell.KeyCtrlG {
+			tq.format(session)
+			return nil
+		}
+		if event.Key() == tcell.KeyCtrlSpace {
+			completion.Open()
+			return nil
+		}
+		if event.Key() == tcell.KeyCtrlX {
+			tq.execute(session, tq.getCurrentStatement())
+			return nil
+		}
+		if event.Key() == tcell.KeyRune && event.Modifiers()&tcell.ModAlt != 0 && event.Rune() == 'x' {
+			tq.execute(session, queryInput.GetText())
+			return nil
+		}
+		if event.Key() == tcell.KeyCtrlS {
+			tq.showSnippets(session)
+			return nil
+		}
+		if event.Key() == tcell.KeyCtrlR {
+			session.SetView(NewHistoryList(tq.PostgreSQLAdapter))
+			return nil
+		}
+		if event.Key() == tcell.KeyCtrlO {
+			tq.editExternally(session, false)
+			return nil
+		}
+		if event.Key() == tcell.KeyRune && event.Modifiers()&tcell.ModAlt != 0 && event.Rune() == 'o' {
+			tq.editExternally(session, true)
 			return nil
 		}
 		return event
 	})
 
+	queryInput.SetChangedFunc(func() {
+		tq.scheduleSave(session)
+	})
+
+	queryResultTable.SetSelectedFunc(func(row, column int) {
+		if tq.grid != nil {
+			inspector := NewRecordInspector(tq.PostgreSQLAdapter, tq.grid, row-1, column)
+			session.ShowLargeModal(inspector)
+		}
+	})
+
 	queryInput.SetBorder(true)
 	queryResultTable.SetBorder(true)
 
 	layout := tview.NewFlex().
 		SetDirection(tview.FlexRow).
-		AddItem(queryInput, 0, 1, true).
-		AddItem(queryResultTable, 0, 3, false)
+		AddItem(bufferTabs, 1, 0, false).
+		AddItem(editor, 0, 1, true).
+		AddItem(resultPane, 0, 3, false)
 
 	layout.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		// The keys of the completion popup are handled by the editor
+		if completion.IsOpen() && queryInput.HasFocus() {
+			return event
+		}
+
+		switch event.Key() {
+		case tcell.KeyCtrlT:
+			tq.addBuffer(session, "", "")
+			return nil
+		case tcell.KeyCtrlN:
+			tq.switchBuffer(session, (tq.workspace.current+1)%len(tq.workspace.buffers))
+			return nil
+		case tcell.KeyCtrlP:
+			count := len(tq.workspace.buffers)
+			tq.switchBuffer(session, (tq.workspace.current-1+count)%count)
+			return nil
+		}
+
+		if event.Key() == tcell.KeyRune && event.Modifiers()&tcell.ModAlt != 0 {
+			if event.Rune() >= '1' && event.Rune() <= '9' {
+				if index := int(event.Rune() - '1'); index < len(tq.workspace.buffers) {
+					tq.switchBuffer(session, index)
+				}
+				return nil
+			}
+
+			switch event.Rune() {
+			case 'w':
+				tq.removeBuffer(session)
+				return nil
+			case 't':
+				tq.toggleTransaction(session)
+				return nil
+			case 'c':
+				tq.endTransaction(session, true)
+				return nil
+			case 'r':
+				tq.endTransaction(session, false)
+				return nil
+			case 'e':
+				tq.explain(session, false)
+				return nil
+			case 'a':
+				tq.explain(session, true)
+				return nil
+			}
+		}
+
 		if event.Key() == tcell.KeyTab {
 			if queryInput.HasFocus() {
 				session.App.SetFocus(queryResultTable)
+				completion.Close()
 			} else {
 				session.App.SetFocus(queryInput)
 			}
 			return nil
 		}
 
+		if queryResultTable.HasFocus() && copyFromGrid(session, tq.grid, queryResultTable, event.Rune()) {
+			return nil
+		}
+
+		if queryResultTable.HasFocus() && (event.Rune() == '[' || event.Rune() == ']') {
+			if count := len(tq.buffer.results); count > 1 {
+				step := 1
+				if event.Rune() == '[' {
+					step = -1
+				}
+				tq.showResult(session, (tq.buffer.current+step+count)%count)
+			}
+			return nil
+		}
+
+		if event.Rune() == 'e' && queryResultTable.HasFocus() && tq.grid != nil {
+			var tx *sql.Tx
+			if tq.transaction != nil {
+				tx = tq.transaction.tx
+			}
+			exportModal := NewExportModal(tq.PostgreSQLAdapter, tq.grid.GetQuery(), "query", tx)
+			session.ShowModal(exportModal)
+			return nil
+		}
+
 		if event.Key() == tcell.KeyEsc {
 			tableList := NewTableList(tq.PostgreSQLAdapter)
 			session.SetView(tableList)
@@ -93,64 +246,609 @@ func (tq *QueryEditor) GetContent(session *session.Session) tview.Primitive {
 		return event
 	})
 
-	return layout
+	tq.loadBuffer(session)
+
+	return &completionLayout{
+		Flex:  layout,
+		popup: completion,
+	}
 }
 
-func (i *QueryEditor) GetKeyBindings() (keybindings []*session.KeyBinding) {
-	keybindings = []*session.KeyBinding{
-		session.NewKeyBinding("<ctrl-x>", "Execute query"),
-		session.NewKeyBinding("<tab>", "Switch focus"),
-		session.NewKeyBinding("<esc>", "Go back to table list"),
+// getCurrentStatement returns the selected text of the editor, or else the
+// statement under the cursor.
+func (tq *QueryEditor) getCurrentStatement() string {
+	text, _, end := tq.queryInput.GetSelection()
+	if text != "" {
+		return text
 	}
 
-	return
+	statements := sqlutil.SplitStatements(tq.queryInput.GetText())
+	statement, _ := sqlutil.StatementAt(statements, end)
+	return statement.Text
 }
 
-func executeQuery(conn *sql.DB, query string) (results []map[string]string, columns []string, err error) {
-	ctx := context.Background()
+// execute runs the statements of a text, after prompting for the values of
+// its :name parameters, if any.
+func (tq *QueryEditor) execute(session *session.Session, text string) {
+	tq.withParameters(session, text, func(text string) {
+		var statements []string
+		for _, statement := range sqlutil.SplitStatements(text) {
+			statements = append(statements, statement.Text)
+		}
+		if len(statements) == 0 {
+			return
+		}
+		if tq.transaction != nil && slices.ContainsFunc(statements, isTransactionControl) {
+			session.ShowMessage("In transaction mode, use <alt-c> and <alt-r> to commit or roll back the transaction.", true)
+			return
+		}
 
-	rows, err := conn.QueryContext(ctx, query)
+		tq.confirmStatements(session, statements, func() {
+			tq.runStatements(session, text, statements)
+		})
+	})
+}
 
-	if err != nil {
-		return nil, nil, err
+// withParameters calls run with a text, once the values of its :name
+// parameters are bound, after prompting for them.
+func (tq *QueryEditor) withParameters(session *session.Session, text string, run func(text string)) {
+	params := sqlutil.NamedParameters(text)
+	if len(params) == 0 {
+		run(text)
+		return
 	}
 
-	defer func() {
-		r_err := rows.Close()
-		if r_err != nil {
-			err = r_err
+	parameterModal := NewParameterModal(tq.PostgreSQLAdapter, params, func(values map[string]string) {
+		run(bindParameters(text, values))
+	})
+	session.ShowModal(parameterModal)
+}
+
+// explain shows the plan of the selected statement, or of the statement under
+// the cursor. With analyze, the statement is executed to get its actual
+// timings and rows.
+func (tq *QueryEditor) explain(session *session.Session, analyze bool) {
+	tq.withParameters(session, tq.getCurrentStatement(), func(text string) {
+		statements := sqlutil.SplitStatements(text)
+		if len(statements) != 1 {
+			session.ShowMessage("Select a single statement to explain.", true)
+			return
+		}
+		statement := statements[0].Text
+
+		if !analyze {
+			tq.runExplain(session, statement, false)
+			return
 		}
+		tq.confirmStatement(session, explainQuery(statement, true), func() {
+			tq.runExplain(session, statement, true)
+		})
+	})
+}
+
+// runExplain explains a statement, in the transaction of the transaction
+// mode if any, and shows its plan.
+func (tq *QueryEditor) runExplain(session *session.Session, statement string, analyze bool) {
+	var tx *sql.Tx
+	if tq.transaction != nil {
+		tx = tq.transaction.tx
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	session.ShowProgress("Explaining query...", cancel)
+	go func() {
+		defer cancel()
+
+		plan, err := tq.explainStatement(ctx, session, statement, analyze, tx)
+		session.CloseProgressAsync()
+
+		session.App.QueueUpdateDraw(func() {
+			if ctx.Err() == context.Canceled {
+				session.ShowMessage("Query cancelled", true)
+				return
+			}
+			if err != nil {
+				session.ShowMessage(fmt.Sprintf("Error: %s", err), true)
+				return
+			}
+			session.ShowLargeModal(NewPlanView(tq.PostgreSQLAdapter, plan))
+		})
 	}()
+}
 
-	var result []map[string]string
-	columns, err = rows.Columns()
+// format pretty-prints the selected text of the editor, or its whole text
+// when nothing is selected.
+func (tq *QueryEditor) format(session *session.Session) {
+	options := sqlutil.FormatOptions{
+		KeywordCase: session.Config.Editor.KeywordCase,
+		Indent:      session.Config.Editor.Indent,
+	}
+
+	text, start, end := tq.queryInput.GetSelection()
+	if text == "" {
+		text, start, end = tq.queryInput.GetText(), 0, tq.queryInput.GetTextLength()
+	}
+	tq.queryInput.Replace(start, end, sqlutil.Format(text, options))
+}
+
+// editExternally edits the text of the editor in the editor of the user
+// ($VISUAL or $EDITOR), and executes the edited text when run is set.
+func (tq *QueryEditor) editExternally(session *session.Session, run bool) {
+	tq.completion.Close()
+	text, err := editExternally(session, tq.queryInput.GetText())
 	if err != nil {
-		return nil, nil, err
+		session.ShowMessage(fmt.Sprintf("Error running the editor:\n%s", err), true)
+		return
+	}
+
+	if text != tq.queryInput.GetText() {
+		tq.queryInput.Replace(0, tq.queryInput.GetTextLength(), text)
+		tq.storeBuffer()
+		tq.saveWorkspace(session)
+	}
+	if run {
+		tq.execute(session, text)
 	}
+}
 
-	for rows.Next() {
-		x := make([]interface{}, len(columns))
-		scans := make([]string, len(columns))
-		row := make(map[string]string)
+// ExecuteCommand handles the commands of the editor, and the commands of the
+// adapter. Snippets are run in this editor, e.g. in its transaction.
+func (tq *QueryEditor) ExecuteCommand(s *session.Session, command string) error {
+	name, args, _ := strings.Cut(strings.TrimSpace(command), " ")
+	args = strings.TrimSpace(args)
 
-		for i := range scans {
-			x[i] = &scans[i]
+	switch name {
+	case "format":
+		tq.format(s)
+	case "begin":
+		if tq.transaction == nil {
+			tq.toggleTransaction(s)
+		}
+	case "commit":
+		tq.endTransaction(s, true)
+	case "rollback":
+		tq.endTransaction(s, false)
+	case "tab":
+		action, name, _ := strings.Cut(args, " ")
+		name = strings.TrimSpace(name)
+		switch action {
+		case "new":
+			tq.addBuffer(s, name, "")
+		case "close":
+			tq.removeBuffer(s)
+			return nil
+		case "rename":
+			if name == "" {
+				return fmt.Errorf("usage: tab rename <name>")
+			}
+			tq.renameBuffer(s, name)
+		default:
+			index, err := strconv.Atoi(action)
+			if err != nil || index < 1 || index > len(tq.workspace.buffers) {
+				return fmt.Errorf("usage: tab new [name] | close | rename <name> | <number>")
+			}
+			tq.switchBuffer(s, index-1)
+		}
+	case "edit":
+		if args != "" && args != "run" {
+			return fmt.Errorf("usage: edit [run]")
+		}
+		tq.editExternally(s, args == "run")
+		return nil
+	case "explain":
+		if args != "" && args != "analyze" {
+			return fmt.Errorf("usage: explain [analyze]")
+		}
+		tq.explain(s, args == "analyze")
+		return nil
+	case "snippet":
+		if args == "" {
+			tq.showSnippets(s)
+			return nil
+		}
+		found, err := snippet.Find(s.Config.Snippets, tq.instance, args)
+		if err != nil {
+			return err
 		}
-		err = rows.Scan(x...)
+		tq.runSnippet(s, *found)
+	default:
+		return tq.PostgreSQLAdapter.ExecuteCommand(s, command)
+	}
+
+	s.App.SetFocus(tq.queryInput)
+	return nil
+}
+
+// showSnippets opens the snippet picker, to run a snippet in the editor.
+func (tq *QueryEditor) showSnippets(session *session.Session) {
+	snippetList := NewSnippetList(tq.PostgreSQLAdapter, func(selected snippet.Snippet) {
+		tq.runSnippet(session, selected)
+	})
+	session.ShowLargeModal(snippetList)
+}
+
+// runSnippet replaces the text of the editor with a snippet and runs it.
+func (tq *QueryEditor) runSnippet(session *session.Session, selected snippet.Snippet) {
+	tq.queryInput.SetText(selected.Query, true)
+	tq.execute(session, selected.Query)
+}
+
+// runStatements executes the statements of a text, and shows their results.
+func (tq *QueryEditor) runStatements(session *session.Session, text string, statements []string) {
+	// In transaction mode, the statements are executed in the transaction
+	var conn queryer
+	transaction := tq.transaction
+	if transaction != nil {
+		conn = transaction.tx
+	}
+
+	buffer := tq.buffer
+	tq.storeBuffer()
+	tq.saveWorkspace(session)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	session.ShowProgress("Executing query...", cancel)
+	go func() {
+		defer cancel()
+
+		start := time.Now()
+		results, err := tq.executeStatements(ctx, session, "query_editor", conn, statements)
+		tq.recordHistory(session, text, start, err)
+		session.CloseProgressAsync()
+
+		session.App.QueueUpdateDraw(func() {
+			if transaction != nil && transaction == tq.transaction {
+				if err != nil {
+					transaction.state = transactionFailed
+				} else if transaction.state == transactionBegin {
+					transaction.state = transactionActive
+				}
+				session.RefreshHeader()
+			}
+
+			// A failed statement alone keeps the previous results
+			if len(results) > 1 || err == nil {
+				tq.setResults(session, buffer, results)
+			}
+
+			if ctx.Err() == context.Canceled {
+				session.ShowMessage("Query cancelled", true)
+			} else if err != nil {
+				session.ShowMessage(fmt.Sprintf("Error: %s", err), true)
+			}
+		})
+	}()
+}
+
+// toggleTransaction enters the transaction mode, or leaves it when no
+// statement was executed in the transaction.
+func (tq *QueryEditor) toggleTransaction(session *session.Session) {
+	if tq.transaction == nil {
+		start := time.Now()
+		transaction, err := beginTransaction(tq.conn)
+		tq.audit(session, "query_editor", "BEGIN", start, 0, err)
 		if err != nil {
-			return nil, nil, err
+			session.ShowMessage(fmt.Sprintf("Error starting the transaction:\n%s", err), true)
+			return
 		}
+		tq.transaction = transaction
+		session.RefreshHeader()
+		return
+	}
 
-		for i, v := range scans {
-			row[columns[i]] = v
+	if tq.transaction.IsPending() {
+		session.ShowMessage("Commit or roll back the transaction before leaving the transaction mode.", true)
+		return
+	}
+	tq.closeTransaction(session)
+	session.RefreshHeader()
+}
+
+// closeTransaction rolls back the transaction of the transaction mode, and
+// leaves the mode.
+func (tq *QueryEditor) closeTransaction(session *session.Session) {
+	start := time.Now()
+	err := tq.transaction.Close()
+	tq.audit(session, "query_editor", "ROLLBACK", start, 0, err)
+	tq.transaction = nil
+}
+
+// endTransaction commits or rolls back the transaction of the transaction
+// mode, and starts a new one.
+func (tq *QueryEditor) endTransaction(session *session.Session, commit bool) {
+	if tq.transaction == nil {
+		return
+	}
+
+	statement, end := "ROLLBACK", tq.transaction.Rollback
+	if commit {
+		statement, end = "COMMIT", tq.transaction.Commit
+	}
+
+	start := time.Now()
+	err := end()
+	tq.audit(session, "query_editor", statement, start, 0, err)
+
+	// The next transaction is started even when ending this one failed
+	start = time.Now()
+	b_err := tq.transaction.begin()
+	tq.audit(session, "query_editor", "BEGIN", start, 0, b_err)
+	if err == nil {
+		err = b_err
+	}
+	session.RefreshHeader()
+	if err != nil {
+		session.ShowMessage(fmt.Sprintf("Error: %s", err), true)
+		return
+	}
+	session.ShowNotice(statement)
+}
+
+// setResults sets the new results of a buffer, releasing the previous ones.
+// The last result is shown first, if the buffer is the one shown, and the
+// cursors of the results of a hidden buffer are released at once.
+func (tq *QueryEditor) setResults(session *session.Session, buffer *editorBuffer, results []*statementResult) {
+	if buffer == tq.buffer {
+		tq.detachResults()
+	}
+	buffer.closeResults()
+	buffer.results = results
+	buffer.current = len(results) - 1
+
+	if buffer == tq.buffer {
+		tq.showResults(session)
+	} else {
+		buffer.releaseResults()
+	}
+}
+
+// showResults shows the results of the buffer shown, or an empty result table
+// when it has none.
+func (tq *QueryEditor) showResults(session *session.Session) {
+	height := 0
+	if len(tq.buffer.results) > 1 {
+		height = 1
+	}
+	tq.resultPane.ResizeItem(tq.resultTabs, height, 0)
+
+	if len(tq.buffer.results) > 0 {
+		tq.showResult(session, tq.buffer.current)
+		return
+	}
+
+	tq.grid = nil
+	tq.resultTable.SetContent(nil)
+	tq.resultTable.Clear()
+	tq.resultTable.SetTitle("")
+}
+
+// detachResults stops the refresh of the result table by the results of the
+// buffer shown.
+func (tq *QueryEditor) detachResults() {
+	for _, result := range tq.buffer.results {
+		if result.grid != nil {
+			result.grid.SetChangedFunc(nil)
 		}
-		result = append(result, row)
 	}
+}
+
+// showResult shows one of the results of the buffer in the result table.
+func (tq *QueryEditor) showResult(session *session.Session, index int) {
+	tq.detachResults()
 
-	return result, columns, nil
+	tq.buffer.current = index
+	result := tq.buffer.results[index]
+	tq.grid = result.grid
+	tq.updateResultTabs()
+
+	table := tq.resultTable
+	if grid := result.grid; grid != nil {
+		grid.SetChangedFunc(func() {
+			session.App.QueueUpdateDraw(func() {
+				table.SetTitle(grid.GetStatus())
+				tq.updateResultTabs()
+			})
+		})
+		table.SetContent(grid)
+		table.SetTitle(grid.GetStatus())
+		table.ScrollToBeginning()
+		return
+	}
+
+	table.SetContent(nil)
+	table.Clear()
+	if result.err != nil {
+		table.SetTitle("Error")
+		table.SetCell(0, 0, tview.NewTableCell(tview.Escape(result.err.Error())).
+			SetTextColor(tcell.ColorRed).
+			SetSelectable(false))
+	} else {
+		table.SetTitle(result.tag)
+		table.SetCell(0, 0, tview.NewTableCell(tview.Escape(result.tag)).
+			SetTextColor(tcell.ColorGreen).
+			SetSelectable(false))
+	}
+	table.SetCell(1, 0, tview.NewTableCell(tview.Escape(result.statement)).
+		SetTextColor(tcell.ColorGray).
+		SetSelectable(false))
+	table.ScrollToBeginning()
+}
+
+// updateResultTabs draws the tabs of the results, highlighting the shown one.
+func (tq *QueryEditor) updateResultTabs() {
+	var tabs []string
+	for i, result := range tq.buffer.results {
+		label := tview.Escape(result.GetLabel())
+		if result.err != nil {
+			label = fmt.Sprintf("[red]%s[-]", label)
+		}
+		tabs = append(tabs, fmt.Sprintf(`["%d"] %d: %s [""]`, i, i+1, label))
+	}
+	tq.resultTabs.SetText(strings.Join(tabs, "│"))
+	tq.resultTabs.Highlight(strconv.Itoa(tq.buffer.current))
+}
+
+// storeBuffer keeps the text of the editor and its selection in the buffer
+// shown.
+func (tq *QueryEditor) storeBuffer() {
+	_, start, end := tq.queryInput.GetSelection()
+	tq.buffer.text = tq.queryInput.GetText()
+	tq.buffer.start, tq.buffer.end = start, end
+}
+
+// scheduleSave saves the buffers once the text has not changed for
+// workspaceSaveDelay.
+func (tq *QueryEditor) scheduleSave(session *session.Session) {
+	if tq.saveTimer != nil {
+		tq.saveTimer.Stop()
+	}
+	tq.saveTimer = time.AfterFunc(workspaceSaveDelay, func() {
+		session.App.QueueUpdate(func() {
+			// The editor may have been left for another database
+			if tq.buffer == nil || tq.workspace != tq.PostgreSQLAdapter.workspace {
+				return
+			}
+			tq.storeBuffer()
+			tq.saveWorkspace(session)
+		})
+	})
+}
+
+// loadBuffer shows the current buffer of the workspace, with its results.
+func (tq *QueryEditor) loadBuffer(session *session.Session) {
+	tq.buffer = tq.workspace.Current()
+	tq.queryInput.SetText(tq.buffer.text, false)
+	tq.queryInput.Select(tq.buffer.start, tq.buffer.end)
+	tq.completion.Close()
+	tq.updateBufferTabs()
+	tq.showResults(session)
+}
+
+// switchBuffer shows another buffer.
+func (tq *QueryEditor) switchBuffer(session *session.Session, index int) {
+	if index == tq.workspace.current {
+		return
+	}
+
+	tq.storeBuffer()
+	tq.detachResults()
+	tq.buffer.releaseResults()
+	tq.workspace.current = index
+	tq.loadBuffer(session)
+	tq.saveWorkspace(session)
+}
+
+// addBuffer opens a new buffer, named after its position when name is empty.
+func (tq *QueryEditor) addBuffer(session *session.Session, name string, text string) {
+	tq.storeBuffer()
+	tq.detachResults()
+	tq.buffer.releaseResults()
+	tq.workspace.Add(name, text)
+	tq.loadBuffer(session)
+	tq.saveWorkspace(session)
+}
+
+// removeBuffer closes the buffer shown, after a confirmation when it is not
+// empty.
+func (tq *QueryEditor) removeBuffer(s *session.Session) {
+	remove := func() {
+		tq.detachResults()
+		tq.workspace.Remove(tq.workspace.current)
+		tq.loadBuffer(s)
+		tq.saveWorkspace(s)
+	}
+
+	tq.storeBuffer()
+	if strings.TrimSpace(tq.buffer.text) == "" {
+		remove()
+		return
+	}
+
+	message := fmt.Sprintf("Close the buffer %s? Its text is discarded.", tq.buffer.name)
+	s.ShowAlert(message, func(s *session.Session) {
+		remove()
+	}, func(s *session.Session) {})
+}
+
+// renameBuffer renames the buffer shown.
+func (tq *QueryEditor) renameBuffer(session *session.Session, name string) {
+	tq.buffer.name = name
+	tq.updateBufferTabs()
+	tq.storeBuffer()
+	tq.saveWorkspace(session)
+}
+
+// updateBufferTabs draws the tabs of the buffers, highlighting the shown one.
+func (tq *QueryEditor) updateBufferTabs() {
+	var tabs []string
+	for i, buffer := range tq.workspace.buffers {
+		tabs = append(tabs, fmt.Sprintf(`["%d"] %d: %s [""]`, i, i+1, tview.Escape(buffer.name)))
+	}
+	tq.bufferTabs.SetText(strings.Join(tabs, "│"))
+	tq.bufferTabs.Highlight(strconv.Itoa(tq.workspace.current))
+	tq.bufferTabs.ScrollToHighlight()
+}
+
+// GetLeaveWarning warns before leaving the editor with statements executed
+// in the transaction of the transaction mode.
+func (tq *QueryEditor) GetLeaveWarning() string {
+	if tq.transaction == nil || !tq.transaction.IsPending() {
+		return ""
+	}
+	return fmt.Sprintf("The transaction of the query editor is open (%s).\nLeaving the editor rolls it back.\n\nLeave anyway?", tq.transaction.state)
+}
+
+// Leave saves the buffers for the next time the editor is opened, and
+// releases the cursors of their results and the transaction.
+func (tq *QueryEditor) Leave() {
+	if tq.saveTimer != nil {
+		tq.saveTimer.Stop()
+	}
+	tq.storeBuffer()
+	tq.saveWorkspace(tq.session)
+	tq.detachResults()
+	tq.workspace.closeResults()
+	tq.grid = nil
+
+	if tq.transaction != nil {
+		tq.closeTransaction(tq.session)
+	}
+}
+
+func (i *QueryEditor) GetKeyBindings() (keybindings []*session.KeyBinding) {
+	keybindings = []*session.KeyBinding{
+		session.NewKeyBinding("<ctrl-x>", "Execute statement"),
+		session.NewKeyBinding("<alt-x>", "Execute all"),
+		session.NewKeyBinding("<ctrl-c>", "Cancel running query"),
+		session.NewKeyBinding("<ctrl-r>", "Query history"),
+		session.NewKeyBinding("<ctrl-s>", "Snippets"),
+		session.NewKeyBinding("<ctrl-space>", "Complete"),
+		session.NewKeyBinding("<ctrl-g>", "Format SQL"),
+		session.NewKeyBinding("<ctrl-o>", "Open in $EDITOR"),
+		session.NewKeyBinding("<alt-o>", "Edit in $EDITOR and execute"),
+		session.NewKeyBinding("<alt-e>", "Explain"),
+		session.NewKeyBinding("<alt-a>", "Explain analyze"),
+		session.NewKeyBinding("<ctrl-t>", "New buffer"),
+		session.NewKeyBinding("<ctrl-n/p>", "Next/previous buffer"),
+		session.NewKeyBinding("<alt-w>", "Close buffer"),
+		session.NewKeyBinding("<alt-t>", "Transaction mode"),
+		session.NewKeyBinding("<alt-c>", "Commit"),
+		session.NewKeyBinding("<alt-r>", "Rollback"),
+		session.NewKeyBinding("<tab>", "Switch focus"),
+		session.NewKeyBinding("<enter>", "Inspect selected row"),
+		session.NewKeyBinding("e", "Export result"),
+		session.NewKeyBinding("[ ]", "Previous/next result"),
+		session.NewKeyBinding("<esc>", "Go back to table list"),
+	}
+	keybindings = append(keybindings, copyKeyBindings...)
+
+	return
 }
 
 func (i *QueryEditor) GetInfo() (info []session.Info) {
 	info = i.PostgreSQLAdapter.GetInfo()
+	if i.transaction != nil {
+		info = append(info, session.NewInfo("Transaction", i.transaction.state))
+	}
 	return
 }
@@ -0,0 +1,277 @@
+package postgresql
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/rhariady/csql/pkg/session"
+)
+
+// RecordInspector shows a row of a result vertically, one column per line,
+// with the full value of the selected column. JSON values and arrays are shown
+// as a tree.
+type RecordInspector struct {
+	*PostgreSQLAdapter
+
+	grid   *ResultGrid
+	row    int
+	column int
+
+	frame      *tview.Flex
+	fieldTable *tview.Table
+	valuePane  *tview.Flex
+	record     resultRow
+}
+
+// NewRecordInspector creates an inspector of the row of a grid, by index
+// starting at 0, with the given column selected.
+func NewRecordInspector(adapter *PostgreSQLAdapter, grid *ResultGrid, row int, column int) *RecordInspector {
+	return &RecordInspector{
+		PostgreSQLAdapter: adapter,
+		grid:              grid,
+		row:               row,
+		column:            column,
+	}
+}
+
+func (r *RecordInspector) GetTitle() string {
+	return "Record"
+}
+
+func (r *RecordInspector) GetContent(s *session.Session) tview.Primitive {
+	r.fieldTable = tview.NewTable().
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	r.fieldTable.SetBorder(true)
+
+	r.valuePane = tview.NewFlex().SetDirection(tview.FlexRow)
+	r.valuePane.SetBorder(true)
+
+	r.frame = tview.NewFlex().
+		AddItem(r.fieldTable, 0, 1, true).
+		AddItem(r.valuePane, 0, 1, false)
+
+	r.fieldTable.SetSelectionChangedFunc(func(row, column int) {
+		if row > 0 {
+			r.column = row - 1
+			r.showValue()
+		}
+	})
+
+	r.frame.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyTab:
+			if r.fieldTable.HasFocus() {
+				s.App.SetFocus(r.valuePane)
+			} else {
+				s.App.SetFocus(r.fieldTable)
+			}
+			return nil
+		case event.Rune() == 'n' && r.fieldTable.HasFocus():
+			r.loadRecord(s, r.row+1)
+			return nil
+		case event.Rune() == 'p' && r.fieldTable.HasFocus():
+			r.loadRecord(s, r.row-1)
+			return nil
+		case event.Rune() == 'c' && r.fieldTable.HasFocus():
+			r.copyValue(s)
+			return nil
+		}
+		return event
+	})
+
+	r.loadRecord(s, r.row)
+
+	return r.frame
+}
+
+// loadRecord shows another row of the result, once it has been fetched.
+func (r *RecordInspector) loadRecord(s *session.Session, row int) {
+	if row < 0 || row > r.grid.GetLoadedRowCount() {
+		return
+	}
+
+	record, ok := r.grid.GetRecord(row)
+	if ok {
+		r.showRecord(row, record)
+		return
+	}
+
+	r.fieldTable.SetTitle(fmt.Sprintf("Loading row %d...", row+1))
+	go func() {
+		record, ok := r.grid.waitRecord(row)
+		s.App.QueueUpdateDraw(func() {
+			if !ok {
+				r.fieldTable.SetTitle(r.getRecordTitle())
+				return
+			}
+			r.showRecord(row, record)
+		})
+	}()
+}
+
+func (r *RecordInspector) showRecord(row int, record resultRow) {
+	r.row = row
+	r.record = record
+
+	columns := r.grid.getResultColumns()
+
+	r.fieldTable.Clear()
+	r.fieldTable.SetCell(0, 0, tview.NewTableCell("Column").SetSelectable(false).SetAttributes(tcell.AttrBold))
+	r.fieldTable.SetCell(0, 1, tview.NewTableCell("Type").SetSelectable(false).SetAttributes(tcell.AttrBold))
+	r.fieldTable.SetCell(0, 2, tview.NewTableCell("Value").SetSelectable(false).SetAttributes(tcell.AttrBold))
+
+	for i, column := range columns {
+		r.fieldTable.SetCell(i+1, 0, tview.NewTableCell(tview.Escape(column.Name)))
+		r.fieldTable.SetCell(i+1, 1, tview.NewTableCell(tview.Escape(column.Type)).SetTextColor(tcell.ColorGray))
+		r.fieldTable.SetCell(i+1, 2, column.valueCell(record[i]).SetAlign(tview.AlignLeft).SetExpansion(1))
+	}
+
+	r.fieldTable.SetTitle(r.getRecordTitle())
+	r.column = min(max(r.column, 0), len(columns)-1)
+	r.fieldTable.Select(r.column+1, 0)
+	r.showValue()
+}
+
+func (r *RecordInspector) getRecordTitle() string {
+	if r.grid.IsDone() {
+		return fmt.Sprintf("Row %d of %d", r.row+1, r.grid.GetLoadedRowCount())
+	}
+	return fmt.Sprintf("Row %d of %d+", r.row+1, r.grid.GetLoadedRowCount())
+}
+
+// showValue shows the full value of the selected column.
+func (r *RecordInspector) showValue() {
+	columns := r.grid.getResultColumns()
+	if r.record == nil || r.column < 0 || r.column >= len(columns) {
+		return
+	}
+
+	column := columns[r.column]
+	value := r.record[r.column]
+	text := column.formatValue(value, true)
+
+	r.valuePane.Clear()
+	r.valuePane.SetTitle(fmt.Sprintf("%s (%s)", column.Name, column.Type))
+
+	if value != nil && (column.IsJSON() || column.IsArray()) {
+		if root, err := newJSONTree(column.Name, text); err == nil {
+			tree := tview.NewTreeView().
+				SetRoot(root).
+				SetCurrentNode(root)
+			r.valuePane.AddItem(tree, 0, 1, true)
+			return
+		}
+	}
+
+	textView := tview.NewTextView().
+		SetWrap(true).
+		SetText(text)
+	if value == nil {
+		textView.SetTextColor(tcell.ColorGray)
+	}
+	r.valuePane.AddItem(textView, 0, 1, true)
+}
+
+// copyValue copies the full value of the selected column to the clipboard.
+func (r *RecordInspector) copyValue(s *session.Session) {
+	columns := r.grid.getResultColumns()
+	if r.record == nil || r.column < 0 || r.column >= len(columns) {
+		return
+	}
+
+	column := columns[r.column]
+	copyText(s, column.exportText(r.record[r.column]), fmt.Sprintf("%s copied to the clipboard", column.Name))
+}
+
+// newJSONTree builds a tree of the nodes of a JSON document, keeping the order
+// of the keys of the objects.
+func newJSONTree(name string, text string) (*tview.TreeNode, error) {
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.UseNumber()
+
+	root, err := decodeJSONNode(decoder, name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after JSON value")
+	}
+
+	return root, nil
+}
+
+func decodeJSONNode(decoder *json.Decoder, label string) (*tview.TreeNode, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	label = tview.Escape(label)
+	node := tview.NewTreeNode("")
+	node.SetSelectedFunc(func() {
+		node.SetExpanded(!node.IsExpanded())
+	})
+
+	switch delim := token.(type) {
+	case json.Delim:
+		count := 0
+		for decoder.More() {
+			var childLabel string
+			if delim == '{' {
+				key, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				childLabel = fmt.Sprint(key)
+			} else {
+				childLabel = fmt.Sprintf("[%d]", count)
+			}
+
+			child, err := decodeJSONNode(decoder, childLabel)
+			if err != nil {
+				return nil, err
+			}
+			node.AddChild(child)
+			count++
+		}
+		// Closing delimiter
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+
+		if delim == '{' {
+			node.SetText(fmt.Sprintf("%s {%d}", label, count))
+		} else {
+			node.SetText(fmt.Sprintf("%s %s", label, tview.Escape(fmt.Sprintf("[%d]", count))))
+		}
+		node.SetColor(tcell.ColorYellow)
+	case nil:
+		node.SetText(fmt.Sprintf("%s: null", label)).SetColor(tcell.ColorGray)
+	case string:
+		value, _ := json.Marshal(delim)
+		node.SetText(fmt.Sprintf("%s: %s", label, tview.Escape(string(value)))).SetColor(tcell.ColorLightGreen)
+	default:
+		node.SetText(fmt.Sprintf("%s: %v", label, delim)).SetColor(tcell.ColorLightSkyBlue)
+	}
+
+	return node, nil
+}
+
+func (r *RecordInspector) GetKeyBindings() (keybindings []*session.KeyBinding) {
+	keybindings = []*session.KeyBinding{
+		session.NewKeyBinding("n/p", "Next/previous row"),
+		session.NewKeyBinding("c", "Copy value"),
+		session.NewKeyBinding("<tab>", "Switch focus"),
+	}
+	return
+}
+
+func (r *RecordInspector) GetInfo() (info []session.Info) {
+	return
+}
@@ -0,0 +1,102 @@
+package postgresql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rivo/tview"
+
+	"github.com/rhariady/csql/pkg/clipboard"
+	"github.com/rhariady/csql/pkg/session"
+)
+
+// copyKeyBindings are the key bindings of copyFromGrid.
+var copyKeyBindings = []*session.KeyBinding{
+	session.NewKeyBinding("c/C", "Copy cell/column"),
+	session.NewKeyBinding("r/R", "Copy row as TSV/JSON"),
+}
+
+// copyFromGrid copies a part of the result shown in a table to the clipboard,
+// depending on the key: c copies the selected cell, r the selected row as TSV,
+// R the selected row as a JSON object and C the loaded values of the selected
+// column. It returns false when the key is not a copy key.
+func copyFromGrid(s *session.Session, grid *ResultGrid, table *tview.Table, key rune) bool {
+	if key != 'c' && key != 'r' && key != 'R' && key != 'C' {
+		return false
+	}
+	if grid == nil {
+		return true
+	}
+
+	row, column := table.GetSelection()
+	columns := grid.getResultColumns()
+	if column < 0 || column >= len(columns) {
+		return true
+	}
+
+	if key == 'C' {
+		copyColumn(s, grid, column)
+		return true
+	}
+
+	record, ok := grid.GetRecord(row - 1)
+	if !ok {
+		s.ShowMessage("The selected row is not loaded yet.", true)
+		return true
+	}
+
+	var text, what string
+	switch key {
+	case 'c':
+		text = columns[column].exportText(record[column])
+		what = "Cell"
+	case 'r':
+		fields := make([]string, len(record))
+		for i, value := range record {
+			fields[i] = columns[i].exportText(value)
+		}
+		text = strings.Join(fields, "\t")
+		what = "Row"
+	case 'R':
+		var err error
+		text, err = recordJSON(columns, record)
+		if err != nil {
+			s.ShowMessage(fmt.Sprintf("Error: %s", err), true)
+			return true
+		}
+		what = "Row"
+	}
+
+	copyText(s, text, fmt.Sprintf("%s copied to the clipboard", what))
+	return true
+}
+
+// copyColumn copies the values of a column, one per line, fetching again the
+// rows that are not in memory anymore.
+func copyColumn(s *session.Session, grid *ResultGrid, column int) {
+	columns := grid.getResultColumns()
+	count := grid.GetLoadedRowCount()
+
+	go func() {
+		values := make([]string, 0, count)
+		for i := 0; i < count; i++ {
+			record, ok := grid.waitRecord(i)
+			if !ok {
+				break
+			}
+			values = append(values, columns[column].exportText(record[column]))
+		}
+
+		s.App.QueueUpdateDraw(func() {
+			copyText(s, strings.Join(values, "\n"), fmt.Sprintf("%d values of %s copied to the clipboard", len(values), columns[column].Name))
+		})
+	}()
+}
+
+func copyText(s *session.Session, text string, message string) {
+	if err := clipboard.Copy(text); err != nil {
+		s.ShowMessage(fmt.Sprintf("Error copying to the clipboard:\n%s", err), true)
+		return
+	}
+	s.ShowNotice(message)
+}
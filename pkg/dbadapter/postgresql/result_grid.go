@@ -0,0 +1,557 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/rhariady/csql/pkg/sqlutil"
+)
+
+const (
+	resultCursorName = "csql_result"
+	// resultPageSize is the number of rows fetched at once from the cursor.
+	resultPageSize = 200
+	// resultMaxPages is the number of pages kept in memory. Older pages are
+	// fetched again from the cursor when they are scrolled back into view.
+	resultMaxPages = 50
+)
+
+type resultRow = []any
+
+// errResultReleased is the error of a result whose cursor has been released
+// before all its rows were fetched.
+var errResultReleased = errors.New("the cursor was released, run the query again to see the other rows")
+
+// queryer executes statements. It is implemented by *sql.DB, *sql.Conn and
+// *sql.Tx.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// ResultGrid is a tview.TableContent showing the rows of a query. Read-only
+// queries are streamed from a server-side scrollable cursor: rows are fetched
+// by pages as the table is scrolled, and only the most recently used pages are
+// kept in memory. Other statements are fully loaded when executed.
+type ResultGrid struct {
+	tview.TableContentReadOnly
+
+	query string
+
+	mu       sync.Mutex
+	columns  []resultColumn
+	pages    map[int][]resultRow
+	recent   []int
+	loaded   int
+	done     bool
+	err      error
+	wanted   map[int]bool
+	fetching bool
+	// released is set once the cursor is released, the rows in memory being
+	// kept
+	released bool
+	changed  func()
+	// marks are shown after the names of the columns in the header, by name
+	marks map[string]string
+	// decorate changes the cells of the rows, e.g. to show pending changes
+	decorate func(columns []resultColumn, record resultRow, column int, cell *tview.TableCell)
+	// fetched is closed and replaced each time a page has been fetched
+	fetched chan struct{}
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	tx     *sql.Tx
+}
+
+// OpenResultGrid executes a query and fetches its first page of rows. ctx only
+// bounds the execution of the query: the cursor stays open until Close.
+func OpenResultGrid(ctx context.Context, conn *sql.DB, query string) (*ResultGrid, error) {
+	gridCtx, cancel := context.WithCancel(context.Background())
+	g := &ResultGrid{
+		query:   query,
+		pages:   make(map[int][]resultRow),
+		wanted:  make(map[int]bool),
+		fetched: make(chan struct{}),
+		ctx:     gridCtx,
+		cancel:  cancel,
+	}
+
+	stop := context.AfterFunc(ctx, cancel)
+	defer stop()
+
+	var err error
+	if sqlutil.IsCursorable(query) {
+		err = g.openCursor(conn, query)
+	} else {
+		err = g.loadAll(conn, query)
+	}
+
+	if err != nil {
+		g.Close()
+		return nil, err
+	}
+
+	return g, nil
+}
+
+// LoadResultGrid executes a query and loads all its rows, without a cursor.
+// It is used for the statements of scripts, which are executed on a single
+// connection.
+func LoadResultGrid(ctx context.Context, conn queryer, query string) (*ResultGrid, error) {
+	gridCtx, cancel := context.WithCancel(context.Background())
+	g := &ResultGrid{
+		query:   query,
+		pages:   make(map[int][]resultRow),
+		wanted:  make(map[int]bool),
+		fetched: make(chan struct{}),
+		ctx:     gridCtx,
+		cancel:  cancel,
+	}
+
+	stop := context.AfterFunc(ctx, cancel)
+	defer stop()
+
+	if err := g.loadAll(conn, query); err != nil {
+		g.Close()
+		return nil, err
+	}
+
+	return g, nil
+}
+
+func (g *ResultGrid) openCursor(conn *sql.DB, query string) error {
+	tx, err := conn.BeginTx(g.ctx, nil)
+	if err != nil {
+		return err
+	}
+	g.tx = tx
+
+	declare := fmt.Sprintf("DECLARE %s SCROLL CURSOR FOR %s", resultCursorName, sqlutil.TrimTerminator(query))
+	if _, err := tx.ExecContext(g.ctx, declare); err != nil {
+		return err
+	}
+
+	return g.fetchPage(0)
+}
+
+func (g *ResultGrid) loadAll(conn queryer, query string) (err error) {
+	rows, err := conn.QueryContext(g.ctx, query)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		r_err := rows.Close()
+		if r_err != nil {
+			err = r_err
+		}
+	}()
+
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return err
+	}
+	g.columns = newResultColumns(columnTypes)
+
+	page := 0
+	for {
+		records, err := scanRows(rows, len(g.columns), resultPageSize)
+		if err != nil {
+			return err
+		}
+		g.pages[page] = records
+		g.loaded += len(records)
+		if len(records) < resultPageSize {
+			break
+		}
+		page++
+	}
+	g.done = true
+
+	return rows.Err()
+}
+
+// fetchPage reads a page of rows from the cursor.
+func (g *ResultGrid) fetchPage(page int) (err error) {
+	move := fmt.Sprintf("MOVE ABSOLUTE %d FROM %s", page*resultPageSize, resultCursorName)
+	if _, err := g.tx.ExecContext(g.ctx, move); err != nil {
+		return err
+	}
+
+	fetch := fmt.Sprintf("FETCH FORWARD %d FROM %s", resultPageSize, resultCursorName)
+	rows, err := g.tx.QueryContext(g.ctx, fetch)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		r_err := rows.Close()
+		if r_err != nil {
+			err = r_err
+		}
+	}()
+
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return err
+	}
+	columns := newResultColumns(columnTypes)
+
+	records, err := scanRows(rows, len(columns), resultPageSize)
+	if err != nil {
+		return err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.released {
+		return nil
+	}
+	g.columns = columns
+	g.pages[page] = records
+	g.touch(page)
+	g.loaded = max(g.loaded, page*resultPageSize+len(records))
+	if len(records) < resultPageSize {
+		g.done = true
+	}
+
+	// Keep the memory bounded by dropping the least recently used pages
+	for len(g.recent) > resultMaxPages {
+		delete(g.pages, g.recent[0])
+		g.recent = g.recent[1:]
+	}
+
+	return nil
+}
+
+func scanRows(rows *sql.Rows, columnCount int, limit int) ([]resultRow, error) {
+	var records []resultRow
+	for len(records) < limit && rows.Next() {
+		record := make(resultRow, columnCount)
+		scans := make([]any, columnCount)
+		for i := range record {
+			scans[i] = &record[i]
+		}
+		if err := rows.Scan(scans...); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+// touch marks a page as the most recently used one. It must be called with
+// the lock held.
+func (g *ResultGrid) touch(page int) {
+	if idx := slices.Index(g.recent, page); idx >= 0 {
+		g.recent = slices.Delete(g.recent, idx, idx+1)
+	}
+	g.recent = append(g.recent, page)
+}
+
+// request schedules the fetch of a page in the background. It must be called
+// with the lock held.
+func (g *ResultGrid) request(page int) {
+	if g.tx == nil || g.released || g.err != nil || g.wanted[page] {
+		return
+	}
+
+	g.wanted[page] = true
+	if !g.fetching {
+		g.fetching = true
+		go g.fetchWanted()
+	}
+}
+
+func (g *ResultGrid) fetchWanted() {
+	for {
+		g.mu.Lock()
+		if len(g.wanted) == 0 || g.err != nil {
+			g.fetching = false
+			g.mu.Unlock()
+			return
+		}
+		var page int
+		for wanted := range g.wanted {
+			page = wanted
+			break
+		}
+		changed := g.changed
+		g.mu.Unlock()
+
+		err := g.fetchPage(page)
+
+		g.mu.Lock()
+		delete(g.wanted, page)
+		if err != nil && g.err == nil {
+			g.err = err
+		}
+		close(g.fetched)
+		g.fetched = make(chan struct{})
+		g.mu.Unlock()
+
+		if changed != nil {
+			changed()
+		}
+	}
+}
+
+// SetColumnMarks sets texts shown after the names of columns in the header,
+// e.g. their sort order.
+func (g *ResultGrid) SetColumnMarks(marks map[string]string) *ResultGrid {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.marks = marks
+	return g
+}
+
+// SetDecorateFunc sets a function changing the cells of the rows before they
+// are drawn. It is called with the lock of the grid held, and must not call
+// the grid.
+func (g *ResultGrid) SetDecorateFunc(decorate func(columns []resultColumn, record resultRow, column int, cell *tview.TableCell)) *ResultGrid {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.decorate = decorate
+	return g
+}
+
+// SetChangedFunc sets a handler called, from a background goroutine, when new
+// rows have been fetched.
+func (g *ResultGrid) SetChangedFunc(handler func()) *ResultGrid {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.changed = handler
+	return g
+}
+
+// Close stops the streaming of rows and releases the cursor.
+func (g *ResultGrid) Close() {
+	g.cancel()
+	if g.tx != nil {
+		// The transaction is rolled back on cancellation already
+		_ = g.tx.Rollback()
+	}
+}
+
+// Release closes the cursor but keeps the rows in memory, so that the result
+// can still be shown without holding a transaction open. The rows after the
+// first page which is no longer in memory are dropped.
+func (g *ResultGrid) Release() {
+	if g.tx == nil {
+		return
+	}
+	g.Close()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.released {
+		return
+	}
+	g.released = true
+	clear(g.wanted)
+	if g.done {
+		if len(g.pages)*resultPageSize >= g.loaded {
+			return
+		}
+		g.done = false
+	}
+
+	loaded := 0
+	for page := 0; ; page++ {
+		records, ok := g.pages[page]
+		if !ok {
+			break
+		}
+		loaded += len(records)
+	}
+	for page := range g.pages {
+		if page*resultPageSize >= loaded {
+			delete(g.pages, page)
+		}
+	}
+	g.loaded = loaded
+	if g.err == nil {
+		g.err = errResultReleased
+	}
+}
+
+// GetQuery returns the query of the result.
+func (g *ResultGrid) GetQuery() string {
+	return g.query
+}
+
+// GetColumns returns the column names of the result.
+func (g *ResultGrid) GetColumns() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	names := make([]string, len(g.columns))
+	for i, column := range g.columns {
+		names[i] = column.Name
+	}
+	return names
+}
+
+// getResultColumns returns the names and types of the columns of the result.
+func (g *ResultGrid) getResultColumns() []resultColumn {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.columns
+}
+
+// GetRecord returns a row of the result, by index starting at 0. ok is false
+// when the row is not in memory, in which case it is fetched in the background
+// and the changed handler is called once it is available.
+func (g *ResultGrid) GetRecord(idx int) (record resultRow, ok bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if idx < 0 || (g.done && idx >= g.loaded) {
+		return nil, false
+	}
+
+	page := idx / resultPageSize
+	records, ok := g.pages[page]
+	if !ok || idx%resultPageSize >= len(records) {
+		g.request(page)
+		return nil, false
+	}
+	g.touch(page)
+
+	return records[idx%resultPageSize], true
+}
+
+// waitRecord returns a row of the result, waiting for it to be fetched if it
+// is not in memory. ok is false when the row does not exist or could not be
+// fetched.
+func (g *ResultGrid) waitRecord(idx int) (record resultRow, ok bool) {
+	for {
+		record, ok := g.GetRecord(idx)
+		if ok {
+			return record, true
+		}
+
+		g.mu.Lock()
+		fetched := g.fetched
+		failed := g.err != nil || (g.done && idx >= g.loaded) || idx < 0
+		g.mu.Unlock()
+		if failed {
+			return nil, false
+		}
+
+		select {
+		case <-fetched:
+		case <-g.ctx.Done():
+			return nil, false
+		}
+	}
+}
+
+// HasColumns reports whether the statement returned a result set, as opposed
+// to the statements like UPDATE which only report a command tag.
+func (g *ResultGrid) HasColumns() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.columns) > 0
+}
+
+// IsDone reports whether all the rows of the result have been fetched.
+func (g *ResultGrid) IsDone() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.done
+}
+
+// GetLoadedRowCount returns the number of rows fetched so far.
+func (g *ResultGrid) GetLoadedRowCount() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.loaded
+}
+
+// GetStatus describes the number of rows of the result.
+func (g *ResultGrid) GetStatus() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	switch {
+	case g.err != nil:
+		return fmt.Sprintf("%d rows loaded, error: %s", g.loaded, g.err)
+	case g.done:
+		return fmt.Sprintf("%d rows", g.loaded)
+	default:
+		return fmt.Sprintf("%d rows loaded, more available", g.loaded)
+	}
+}
+
+func (g *ResultGrid) GetCell(row, column int) *tview.TableCell {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if column >= len(g.columns) {
+		return nil
+	}
+
+	if row == 0 {
+		return g.columns[column].headerCell(g.marks[g.columns[column].Name])
+	}
+
+	idx := row - 1
+	if idx >= g.loaded {
+		// Last row, shown while more rows are available
+		if g.done {
+			return nil
+		}
+		g.request(g.loaded / resultPageSize)
+		if column != 0 {
+			return tview.NewTableCell("")
+		}
+		if g.err != nil {
+			return tview.NewTableCell(tview.Escape(g.err.Error())).SetTextColor(tcell.ColorRed)
+		}
+		return tview.NewTableCell("Loading more rows...").SetTextColor(tcell.ColorGray)
+	}
+
+	page := idx / resultPageSize
+	records, ok := g.pages[page]
+	if !ok {
+		g.request(page)
+		return tview.NewTableCell("…").SetTextColor(tcell.ColorGray)
+	}
+	g.touch(page)
+
+	record := records[idx%resultPageSize]
+	cell := g.columns[column].valueCell(record[column])
+	if g.decorate != nil {
+		g.decorate(g.columns, record, column, cell)
+	}
+	return cell
+}
+
+func (g *ResultGrid) GetRowCount() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if len(g.columns) == 0 {
+		return 0
+	}
+	if g.done {
+		return g.loaded + 1
+	}
+	return g.loaded + 2
+}
+
+func (g *ResultGrid) GetColumnCount() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.columns)
+}
@@ -0,0 +1,139 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/rhariady/csql/pkg/session"
+	"github.com/rhariady/csql/pkg/sqlutil"
+)
+
+// statementResult is the outcome of a statement of a script: the rows it
+// returned, or its command tag, or the error which stopped the script.
+type statementResult struct {
+	statement string
+	grid      *ResultGrid
+	tag       string
+	rows      int64
+	err       error
+}
+
+// GetLabel describes the result in a few words, for its tab.
+func (r *statementResult) GetLabel() string {
+	switch {
+	case errors.Is(r.err, context.Canceled):
+		return "cancelled"
+	case r.err != nil:
+		return "error"
+	case r.grid != nil && r.grid.IsDone():
+		return fmt.Sprintf("%d rows", r.grid.GetLoadedRowCount())
+	case r.grid != nil:
+		return fmt.Sprintf("%d+ rows", r.grid.GetLoadedRowCount())
+	default:
+		return r.tag
+	}
+}
+
+// Close releases the cursor of the result, if any.
+func (r *statementResult) Close() {
+	if r.grid != nil {
+		r.grid.Close()
+	}
+}
+
+// confirmStatements calls run once every statement passed the guardrails of
+// the instance environment. Nothing is run if one of them is not confirmed.
+func (a *PostgreSQLAdapter) confirmStatements(s *session.Session, statements []string, run func()) {
+	if len(statements) == 0 {
+		run()
+		return
+	}
+
+	a.confirmStatement(s, statements[0], func() {
+		a.confirmStatements(s, statements[1:], run)
+	})
+}
+
+// executeStatements executes statements in order, stopping at the first
+// error, which is also returned. They are executed on conn when it is not nil,
+// e.g. in a transaction. Otherwise a single read query is streamed from a
+// cursor, and the statements of a script are executed on one connection, so
+// that they share its session (SET, temporary tables...). The rows of the
+// other queries are fully loaded.
+func (a *PostgreSQLAdapter) executeStatements(ctx context.Context, s *session.Session, source string, conn queryer, statements []string) ([]*statementResult, error) {
+	stream := conn == nil && len(statements) == 1
+	if conn == nil {
+		conn = a.conn
+		if len(statements) > 1 {
+			c, err := a.conn.Conn(ctx)
+			if err != nil {
+				return nil, err
+			}
+			defer c.Close()
+			conn = c
+		}
+	}
+
+	var results []*statementResult
+	for _, statement := range statements {
+		start := time.Now()
+		result := a.executeStatement(ctx, conn, statement, stream)
+		a.audit(s, source, statement, start, result.rows, result.err)
+
+		results = append(results, result)
+		if result.err != nil {
+			return results, result.err
+		}
+	}
+
+	return results, nil
+}
+
+func (a *PostgreSQLAdapter) executeStatement(ctx context.Context, conn queryer, statement string, stream bool) *statementResult {
+	result := &statementResult{statement: statement}
+
+	if stream && sqlutil.IsCursorable(statement) {
+		result.grid, result.err = OpenResultGrid(ctx, a.conn, statement)
+		if result.grid != nil {
+			result.rows = int64(result.grid.GetLoadedRowCount())
+		}
+		return result
+	}
+
+	// Writes without RETURNING only report the number of affected rows
+	kind := sqlutil.Classify(statement)
+	returning := slices.ContainsFunc(sqlutil.SignificantTokens(statement), func(token sqlutil.Token) bool {
+		return token.IsKeyword("RETURNING")
+	})
+	if (kind == sqlutil.Write || kind == sqlutil.DDL) && !returning {
+		res, err := conn.ExecContext(ctx, statement)
+		if err != nil {
+			result.err = err
+			return result
+		}
+		rows, err := res.RowsAffected()
+		if err != nil {
+			rows = -1
+		}
+		result.rows = max(rows, 0)
+		result.tag = sqlutil.CommandTag(statement, rows)
+		return result
+	}
+
+	grid, err := LoadResultGrid(ctx, conn, statement)
+	if err != nil {
+		result.err = err
+		return result
+	}
+	if !grid.HasColumns() {
+		grid.Close()
+		result.tag = sqlutil.CommandTag(statement, -1)
+		return result
+	}
+	result.grid = grid
+	result.rows = int64(grid.GetLoadedRowCount())
+	return result
+}
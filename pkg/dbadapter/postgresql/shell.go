@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"strings"
 	"syscall"
 
 	"github.com/creack/pty"
@@ -59,6 +60,14 @@ func (v *ShellView) GetContent(s *session.Session) tview.Primitive {
 	)
 
 	v.cmd = exec.Command("psql", dsn)
+	var options []string
+	for _, param := range v.runtimeParams() {
+		value := strings.ReplaceAll(param[1], " ", `\ `)
+		options = append(options, fmt.Sprintf("-c %s=%s", param[0], value))
+	}
+	if len(options) > 0 {
+		v.cmd.Env = append(os.Environ(), "PGOPTIONS="+strings.Join(options, " "))
+	}
 	v.ptmx, err = pty.Start(v.cmd)
 	if err != nil {
 		s.ShowMessage(fmt.Sprintf("Error starting shell: %s", err), true)
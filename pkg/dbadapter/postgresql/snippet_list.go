@@ -0,0 +1,196 @@
+package postgresql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/lib/pq"
+	"github.com/rivo/tview"
+
+	"github.com/rhariady/csql/pkg/session"
+	"github.com/rhariady/csql/pkg/snippet"
+	"github.com/rhariady/csql/pkg/sqlutil"
+)
+
+// bindParameters replaces the :name parameters of a query with their values,
+// as string literals: their type is resolved by the server from the context.
+func bindParameters(query string, values map[string]string) string {
+	return sqlutil.BindNamedParameters(query, func(name string) string {
+		return pq.QuoteLiteral(values[name])
+	})
+}
+
+// ParameterModal prompts for the values of the :name parameters of a query.
+type ParameterModal struct {
+	*PostgreSQLAdapter
+	params []string
+	run    func(values map[string]string)
+}
+
+func NewParameterModal(adapter *PostgreSQLAdapter, params []string, run func(values map[string]string)) *ParameterModal {
+	return &ParameterModal{
+		PostgreSQLAdapter: adapter,
+		params:            params,
+		run:               run,
+	}
+}
+
+func (p *ParameterModal) GetTitle() string {
+	return "Query Parameters"
+}
+
+func (p *ParameterModal) GetContent(s *session.Session) tview.Primitive {
+	form := tview.NewForm()
+
+	for _, param := range p.params {
+		form.AddInputField(param, p.paramValues[param], 0, nil, nil)
+	}
+
+	form.
+		AddButton("Run", func() {
+			values := make(map[string]string)
+			for _, param := range p.params {
+				values[param] = form.GetFormItemByLabel(param).(*tview.InputField).GetText()
+			}
+
+			// Remember the values for the next executions
+			if p.paramValues == nil {
+				p.paramValues = make(map[string]string)
+			}
+			for param, value := range values {
+				p.paramValues[param] = value
+			}
+
+			s.CloseModal()
+			p.run(values)
+		}).
+		AddButton("Cancel", func() {
+			s.CloseModal()
+		})
+
+	form.SetFieldBackgroundColor(tcell.ColorGray)
+	form.SetButtonBackgroundColor(tcell.ColorDarkGray)
+
+	return form
+}
+
+func (p *ParameterModal) GetKeyBindings() (keybindings []*session.KeyBinding) {
+	return
+}
+
+func (p *ParameterModal) GetInfo() (info []session.Info) {
+	return
+}
+
+// SnippetList lets the user pick one of the snippets available on the
+// instance.
+type SnippetList struct {
+	*PostgreSQLAdapter
+	selected func(snippet snippet.Snippet)
+}
+
+func NewSnippetList(adapter *PostgreSQLAdapter, selected func(snippet snippet.Snippet)) *SnippetList {
+	return &SnippetList{
+		PostgreSQLAdapter: adapter,
+		selected:          selected,
+	}
+}
+
+func (l *SnippetList) GetTitle() string {
+	return "Snippets"
+}
+
+func (l *SnippetList) GetContent(s *session.Session) tview.Primitive {
+	snippetTable := tview.NewTable().
+		SetBorders(false).
+		SetSelectable(true, false)
+
+	filterTable := session.NewFilterTable(snippetTable)
+	filterTable.SetHeader(
+		tview.NewTableCell("Name"),
+		tview.NewTableCell("Scope"),
+		tview.NewTableCell("Description").SetExpansion(1),
+	)
+
+	preview := tview.NewTextView().
+		SetWrap(true)
+	preview.SetBorder(true)
+
+	layout := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(filterTable, 0, 1, true).
+		AddItem(preview, 0, 1, false)
+
+	getSnippet := func(row int) (snippet.Snippet, bool) {
+		if row <= 0 || row >= snippetTable.GetRowCount() {
+			return snippet.Snippet{}, false
+		}
+		selected, ok := snippetTable.GetCell(row, 0).GetReference().(snippet.Snippet)
+		return selected, ok
+	}
+
+	showPreview := func(row int) {
+		preview.SetText("").SetTitle("")
+		if selected, ok := getSnippet(row); ok {
+			preview.SetText(selected.Query).ScrollToBeginning()
+			preview.SetTitle(selected.Path)
+		}
+	}
+
+	snippets, err := snippet.Load(s.Config.Snippets, l.instance)
+	if err != nil {
+		preview.SetText(fmt.Sprintf("Error reading snippets:\n%s", err))
+	}
+	if len(snippets) == 0 && err == nil {
+		paths, _ := snippet.GetPaths(s.Config.Snippets)
+		preview.SetText(fmt.Sprintf("No snippets found in:\n%s", strings.Join(paths, "\n")))
+	}
+
+	var rows [][]*tview.TableCell
+	for _, item := range snippets {
+		rows = append(rows, []*tview.TableCell{
+			tview.NewTableCell(tview.Escape(item.Name)).SetReference(item),
+			tview.NewTableCell(tview.Escape(item.GetScope())),
+			tview.NewTableCell(tview.Escape(item.Description)),
+		})
+	}
+	filterTable.SetRows(rows)
+	if len(rows) > 0 {
+		row, _ := snippetTable.GetSelection()
+		showPreview(row)
+	}
+
+	snippetTable.SetSelectionChangedFunc(func(row, column int) {
+		showPreview(row)
+	})
+
+	snippetTable.SetSelectedFunc(func(row, column int) {
+		if selected, ok := getSnippet(row); ok {
+			s.CloseModal()
+			l.selected(selected)
+		}
+	})
+
+	snippetTable.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Rune() == 'f' {
+			filterTable.Show(s)
+			return nil
+		}
+		return event
+	})
+
+	return layout
+}
+
+func (l *SnippetList) GetKeyBindings() (keybindings []*session.KeyBinding) {
+	keybindings = []*session.KeyBinding{
+		session.NewKeyBinding("<enter>", "Run snippet"),
+		session.NewKeyBinding("[f]", "Filter snippets"),
+	}
+	return
+}
+
+func (l *SnippetList) GetInfo() (info []session.Info) {
+	return
+}
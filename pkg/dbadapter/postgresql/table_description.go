@@ -0,0 +1,542 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// tableDescription is the structure of a table, as shown by psql's \d+, or of
+// another relation: a view, a materialized view or a sequence.
+type tableDescription struct {
+	Schema string
+	Table  string
+	Kind   string
+	Owner  string
+	// Persistence is "unlogged" or "temporary" for such tables
+	Persistence    string
+	Size           string
+	Comment        string
+	Options        []string
+	PartitionKey   string
+	Parents        []string
+	PartitionOf    string
+	PartitionBound string
+	RowSecurity    bool
+	ForceSecurity  bool
+	// Definition is the query of a view or a materialized view, and Populated
+	// is false for a materialized view never refreshed
+	Definition string
+	Populated  bool
+	Sequence   *describedSequence
+
+	Columns     []describedColumn
+	Indexes     []describedIndex
+	Constraints []describedConstraint
+	Triggers    []describedTrigger
+	Policies    []describedPolicy
+	Partitions  []describedPartition
+}
+
+type describedColumn struct {
+	Name      string
+	Type      string
+	Collation string
+	NotNull   bool
+	Default   string
+	// Identity is "a" or "d" for the identity columns generated always or by
+	// default, and Generated "s" for the stored generated columns, whose
+	// expression is Default
+	Identity  string
+	Generated string
+	Comment   string
+	// Local is false for the columns inherited from a parent table
+	Local bool
+}
+
+// getDefault describes the default value of a column as in a column
+// definition.
+func (c describedColumn) getDefault() string {
+	switch {
+	case c.Identity == "a":
+		return "GENERATED ALWAYS AS IDENTITY"
+	case c.Identity == "d":
+		return "GENERATED BY DEFAULT AS IDENTITY"
+	case c.Generated == "s":
+		return fmt.Sprintf("GENERATED ALWAYS AS (%s) STORED", c.Default)
+	case c.Default != "":
+		return "DEFAULT " + c.Default
+	}
+	return ""
+}
+
+type describedIndex struct {
+	Name       string
+	Definition string
+	Size       string
+	// Constraint is set for the indexes of primary key, unique and exclusion
+	// constraints, which are created with their constraint
+	Constraint bool
+	Valid      bool
+}
+
+type describedConstraint struct {
+	Name       string
+	Type       string
+	Definition string
+	Local      bool
+}
+
+type describedTrigger struct {
+	Name       string
+	Enabled    string
+	Definition string
+}
+
+type describedPolicy struct {
+	Name       string
+	Permissive string
+	Roles      string
+	Command    string
+	Using      string
+	Check      string
+}
+
+type describedPartition struct {
+	Name  string
+	Bound string
+	Size  string
+}
+
+type describedSequence struct {
+	Type      string
+	Start     string
+	Increment string
+	Min       string
+	Max       string
+	Cache     string
+	Cycle     bool
+	// LastValue is empty when the sequence was never used, or cannot be read
+	LastValue string
+	// OwnedBy is the column owning the sequence, if any
+	OwnedBy string
+}
+
+// queryEach runs a query and calls scan for each of its rows.
+func (a *PostgreSQLAdapter) queryEach(ctx context.Context, query string, args []any, scan func(rows *sql.Rows) error) (err error) {
+	rows, err := a.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		r_err := rows.Close()
+		if r_err != nil {
+			err = r_err
+		}
+	}()
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// describeTable reads the structure of a table from the catalog.
+func (a *PostgreSQLAdapter) describeTable(ctx context.Context, schema string, table string) (*tableDescription, error) {
+	d := &tableDescription{Schema: schema, Table: table}
+	args := []any{qualifiedName(schema, table)}
+
+	err := a.queryEach(ctx, `SELECT CASE c.relkind WHEN 'r' THEN 'table' WHEN 'p' THEN 'partitioned table'
+    WHEN 'f' THEN 'foreign table' WHEN 'm' THEN 'materialized view' WHEN 'v' THEN 'view' WHEN 'S' THEN 'sequence'
+    ELSE c.relkind::text END,
+pg_catalog.pg_get_userbyid(c.relowner),
+CASE c.relpersistence WHEN 'u' THEN 'unlogged' WHEN 't' THEN 'temporary' ELSE '' END,
+pg_catalog.pg_size_pretty(pg_catalog.pg_total_relation_size(c.oid)),
+COALESCE(pg_catalog.obj_description(c.oid, 'pg_class'), ''),
+COALESCE(c.reloptions, '{}'),
+CASE c.relkind WHEN 'p' THEN pg_catalog.pg_get_partkeydef(c.oid) ELSE '' END,
+ARRAY(SELECT i.inhparent::regclass::text FROM pg_catalog.pg_inherits i WHERE i.inhrelid = c.oid ORDER BY i.inhseqno),
+c.relispartition,
+COALESCE(pg_catalog.pg_get_expr(c.relpartbound, c.oid), ''),
+c.relrowsecurity, c.relforcerowsecurity,
+CASE WHEN c.relkind IN ('v', 'm') THEN pg_catalog.pg_get_viewdef(c.oid, true) ELSE '' END,
+c.relispopulated
+FROM pg_catalog.pg_class c
+WHERE c.oid = $1::regclass`, args, func(rows *sql.Rows) error {
+		var partition bool
+		err := rows.Scan(&d.Kind, &d.Owner, &d.Persistence, &d.Size, &d.Comment, pq.Array(&d.Options),
+			&d.PartitionKey, pq.Array(&d.Parents), &partition, &d.PartitionBound, &d.RowSecurity, &d.ForceSecurity,
+			&d.Definition, &d.Populated)
+		if partition && len(d.Parents) > 0 {
+			d.PartitionOf = d.Parents[0]
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.queryEach(ctx, `SELECT a.attname,
+pg_catalog.format_type(a.atttypid, a.atttypmod),
+COALESCE((SELECT co.collname FROM pg_catalog.pg_collation co, pg_catalog.pg_type t
+    WHERE co.oid = a.attcollation AND t.oid = a.atttypid AND a.attcollation <> t.typcollation), ''),
+a.attnotnull,
+COALESCE(pg_catalog.pg_get_expr(d.adbin, d.adrelid), ''),
+a.attidentity::text, a.attgenerated::text,
+COALESCE(pg_catalog.col_description(a.attrelid, a.attnum), ''),
+a.attislocal
+FROM pg_catalog.pg_attribute a
+    LEFT JOIN pg_catalog.pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+WHERE a.attrelid = $1::regclass
+    AND a.attnum > 0
+    AND NOT a.attisdropped
+ORDER BY a.attnum`, args, func(rows *sql.Rows) error {
+		var column describedColumn
+		err := rows.Scan(&column.Name, &column.Type, &column.Collation, &column.NotNull, &column.Default,
+			&column.Identity, &column.Generated, &column.Comment, &column.Local)
+		d.Columns = append(d.Columns, column)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.queryEach(ctx, `SELECT c.relname,
+pg_catalog.pg_get_indexdef(i.indexrelid),
+pg_catalog.pg_size_pretty(pg_catalog.pg_relation_size(i.indexrelid)),
+EXISTS (SELECT 1 FROM pg_catalog.pg_constraint con
+    WHERE con.conrelid = i.indrelid AND con.conindid = i.indexrelid AND con.contype IN ('p', 'u', 'x')),
+i.indisvalid
+FROM pg_catalog.pg_index i
+    JOIN pg_catalog.pg_class c ON c.oid = i.indexrelid
+WHERE i.indrelid = $1::regclass
+ORDER BY i.indisprimary DESC, c.relname`, args, func(rows *sql.Rows) error {
+		var index describedIndex
+		err := rows.Scan(&index.Name, &index.Definition, &index.Size, &index.Constraint, &index.Valid)
+		d.Indexes = append(d.Indexes, index)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// NOT NULL constraints are shown with the columns
+	err = a.queryEach(ctx, `SELECT c.conname,
+CASE c.contype WHEN 'p' THEN 'primary key' WHEN 'u' THEN 'unique' WHEN 'f' THEN 'foreign key'
+    WHEN 'c' THEN 'check' WHEN 'x' THEN 'exclusion' WHEN 't' THEN 'trigger' ELSE c.contype::text END,
+pg_catalog.pg_get_constraintdef(c.oid, true),
+c.conislocal
+FROM pg_catalog.pg_constraint c
+WHERE c.conrelid = $1::regclass
+    AND c.contype <> 'n'
+ORDER BY CASE c.contype WHEN 'p' THEN 0 WHEN 'u' THEN 1 WHEN 'f' THEN 2 ELSE 3 END, c.conname`, args, func(rows *sql.Rows) error {
+		var constraint describedConstraint
+		err := rows.Scan(&constraint.Name, &constraint.Type, &constraint.Definition, &constraint.Local)
+		d.Constraints = append(d.Constraints, constraint)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.queryEach(ctx, `SELECT t.tgname,
+CASE t.tgenabled WHEN 'O' THEN 'enabled' WHEN 'D' THEN 'disabled' WHEN 'R' THEN 'replica' WHEN 'A' THEN 'always' ELSE t.tgenabled::text END,
+pg_catalog.pg_get_triggerdef(t.oid, true)
+FROM pg_catalog.pg_trigger t
+WHERE t.tgrelid = $1::regclass
+    AND NOT t.tgisinternal
+ORDER BY t.tgname`, args, func(rows *sql.Rows) error {
+		var trigger describedTrigger
+		err := rows.Scan(&trigger.Name, &trigger.Enabled, &trigger.Definition)
+		d.Triggers = append(d.Triggers, trigger)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.queryEach(ctx, `SELECT p.policyname, p.permissive,
+pg_catalog.array_to_string(p.roles, ', '),
+p.cmd,
+COALESCE(p.qual, ''), COALESCE(p.with_check, '')
+FROM pg_catalog.pg_policies p
+WHERE p.schemaname = $1 AND p.tablename = $2
+ORDER BY p.policyname`, []any{schema, table}, func(rows *sql.Rows) error {
+		var policy describedPolicy
+		err := rows.Scan(&policy.Name, &policy.Permissive, &policy.Roles, &policy.Command, &policy.Using, &policy.Check)
+		d.Policies = append(d.Policies, policy)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.queryEach(ctx, `SELECT c.oid::regclass::text,
+COALESCE(pg_catalog.pg_get_expr(c.relpartbound, c.oid), ''),
+pg_catalog.pg_size_pretty(pg_catalog.pg_total_relation_size(c.oid))
+FROM pg_catalog.pg_inherits i
+    JOIN pg_catalog.pg_class c ON c.oid = i.inhrelid
+WHERE i.inhparent = $1::regclass
+ORDER BY c.oid::regclass::text`, args, func(rows *sql.Rows) error {
+		var partition describedPartition
+		err := rows.Scan(&partition.Name, &partition.Bound, &partition.Size)
+		d.Partitions = append(d.Partitions, partition)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if d.Kind == "sequence" {
+		d.Sequence = &describedSequence{}
+		err = a.queryEach(ctx, `SELECT s.data_type::text, s.start_value::text, s.increment_by::text,
+s.min_value::text, s.max_value::text, s.cache_size::text, s.cycle,
+COALESCE(s.last_value::text, ''),
+COALESCE((SELECT d.refobjid::regclass::text || '.' || pg_catalog.quote_ident(a.attname)
+    FROM pg_catalog.pg_depend d
+        JOIN pg_catalog.pg_attribute a ON a.attrelid = d.refobjid AND a.attnum = d.refobjsubid
+    WHERE d.classid = 'pg_catalog.pg_class'::regclass AND d.objid = $3::regclass
+        AND d.refclassid = 'pg_catalog.pg_class'::regclass AND d.deptype IN ('a', 'i')), '')
+FROM pg_catalog.pg_sequences s
+WHERE s.schemaname = $1 AND s.sequencename = $2`, []any{schema, table, args[0]}, func(rows *sql.Rows) error {
+			q := d.Sequence
+			return rows.Scan(&q.Type, &q.Start, &q.Increment, &q.Min, &q.Max, &q.Cache, &q.Cycle, &q.LastValue, &q.OwnedBy)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return d, nil
+}
+
+// DDL reconstructs the statements creating the relation, with its indexes,
+// triggers, policies and comments. Inherited columns and constraints are left
+// to the parent table.
+func (d *tableDescription) DDL() string {
+	name := qualifiedName(d.Schema, d.Table)
+
+	var ddl strings.Builder
+	switch d.Kind {
+	case "view", "materialized view":
+		create := "CREATE VIEW"
+		if d.Kind == "materialized view" {
+			create = "CREATE MATERIALIZED VIEW"
+		}
+		fmt.Fprintf(&ddl, "%s %s", create, name)
+		if len(d.Options) > 0 {
+			fmt.Fprintf(&ddl, "\nWITH (%s)", strings.Join(d.Options, ", "))
+		}
+		fmt.Fprintf(&ddl, " AS\n%s", strings.TrimSuffix(strings.TrimRight(d.Definition, " \n"), ";"))
+		if d.Kind == "materialized view" && !d.Populated {
+			ddl.WriteString("\nWITH NO DATA")
+		}
+	case "sequence":
+		q := d.Sequence
+		fmt.Fprintf(&ddl, "CREATE SEQUENCE %s AS %s\n    INCREMENT BY %s MINVALUE %s MAXVALUE %s\n    START WITH %s CACHE %s",
+			name, q.Type, q.Increment, q.Min, q.Max, q.Start, q.Cache)
+		if q.Cycle {
+			ddl.WriteString(" CYCLE")
+		} else {
+			ddl.WriteString(" NO CYCLE")
+		}
+	default:
+		d.writeCreateTable(&ddl)
+	}
+	ddl.WriteString(";\n")
+
+	var statements []string
+	if d.Sequence != nil && d.Sequence.OwnedBy != "" {
+		statements = append(statements, fmt.Sprintf("ALTER SEQUENCE %s OWNED BY %s;", name, d.Sequence.OwnedBy))
+	}
+	for _, index := range d.Indexes {
+		if !index.Constraint {
+			statements = append(statements, index.Definition+";")
+		}
+	}
+	for _, trigger := range d.Triggers {
+		statements = append(statements, trigger.Definition+";")
+	}
+	if d.RowSecurity {
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s ENABLE ROW LEVEL SECURITY;", name))
+	}
+	if d.ForceSecurity {
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s FORCE ROW LEVEL SECURITY;", name))
+	}
+	for _, policy := range d.Policies {
+		statement := fmt.Sprintf("CREATE POLICY %s ON %s AS %s FOR %s TO %s",
+			pq.QuoteIdentifier(policy.Name), name, policy.Permissive, policy.Command, policy.Roles)
+		if policy.Using != "" {
+			statement += fmt.Sprintf(" USING (%s)", policy.Using)
+		}
+		if policy.Check != "" {
+			statement += fmt.Sprintf(" WITH CHECK (%s)", policy.Check)
+		}
+		statements = append(statements, statement+";")
+	}
+	if d.Comment != "" {
+		statements = append(statements, fmt.Sprintf("COMMENT ON %s %s IS %s;", strings.ToUpper(strings.TrimPrefix(d.Kind, "partitioned ")), name, pq.QuoteLiteral(d.Comment)))
+	}
+	for _, column := range d.Columns {
+		if column.Comment != "" {
+			statements = append(statements, fmt.Sprintf("COMMENT ON COLUMN %s.%s IS %s;", name, pq.QuoteIdentifier(column.Name), pq.QuoteLiteral(column.Comment)))
+		}
+	}
+
+	if len(statements) > 0 {
+		ddl.WriteString("\n" + strings.Join(statements, "\n") + "\n")
+	}
+	return ddl.String()
+}
+
+// writeCreateTable writes the CREATE TABLE statement of the table, without
+// its final semicolon.
+func (d *tableDescription) writeCreateTable(ddl *strings.Builder) {
+	var definitions []string
+	if d.PartitionOf == "" {
+		for _, column := range d.Columns {
+			if !column.Local {
+				continue
+			}
+			definition := fmt.Sprintf("%s %s", pq.QuoteIdentifier(column.Name), column.Type)
+			if column.Collation != "" {
+				definition += " COLLATE " + pq.QuoteIdentifier(column.Collation)
+			}
+			if def := column.getDefault(); def != "" {
+				definition += " " + def
+			}
+			if column.NotNull {
+				definition += " NOT NULL"
+			}
+			definitions = append(definitions, definition)
+		}
+	}
+	for _, constraint := range d.Constraints {
+		if constraint.Local {
+			definitions = append(definitions, fmt.Sprintf("CONSTRAINT %s %s", pq.QuoteIdentifier(constraint.Name), constraint.Definition))
+		}
+	}
+
+	create := "CREATE TABLE"
+	switch {
+	case d.Kind == "foreign table":
+		create = "CREATE FOREIGN TABLE"
+	case d.Persistence == "unlogged":
+		create = "CREATE UNLOGGED TABLE"
+	case d.Persistence == "temporary":
+		create = "CREATE TEMPORARY TABLE"
+	}
+	fmt.Fprintf(ddl, "%s %s", create, qualifiedName(d.Schema, d.Table))
+	if d.PartitionOf != "" {
+		fmt.Fprintf(ddl, " PARTITION OF %s", d.PartitionOf)
+	}
+	if len(definitions) > 0 || d.PartitionOf == "" {
+		fmt.Fprintf(ddl, " (\n    %s\n)", strings.Join(definitions, ",\n    "))
+	}
+	if d.PartitionOf != "" {
+		fmt.Fprintf(ddl, "\n%s", d.PartitionBound)
+	} else if len(d.Parents) > 0 {
+		fmt.Fprintf(ddl, "\nINHERITS (%s)", strings.Join(d.Parents, ", "))
+	}
+	if d.PartitionKey != "" {
+		fmt.Fprintf(ddl, "\nPARTITION BY %s", d.PartitionKey)
+	}
+	if len(d.Options) > 0 {
+		fmt.Fprintf(ddl, "\nWITH (%s)", strings.Join(d.Options, ", "))
+	}
+}
+
+// getSections returns the sections of the description, the empty ones being
+// left out except the columns.
+func (d *tableDescription) getSections() []describeSection {
+	overview := newOverviewSection()
+	overview.addProperty("Type", strings.TrimSpace(d.Persistence+" "+d.Kind))
+	overview.addProperty("Owner", d.Owner)
+	overview.addProperty("Size", d.Size)
+	overview.addProperty("Comment", d.Comment)
+	overview.addProperty("Partition key", d.PartitionKey)
+	if d.PartitionOf != "" {
+		overview.addProperty("Partition of", fmt.Sprintf("%s %s", d.PartitionOf, d.PartitionBound))
+	} else {
+		overview.addProperty("Inherits", strings.Join(d.Parents, ", "))
+	}
+	overview.addProperty("Options", strings.Join(d.Options, ", "))
+	switch {
+	case d.ForceSecurity:
+		overview.addProperty("Row security", "enabled, forced")
+	case d.RowSecurity:
+		overview.addProperty("Row security", "enabled")
+	}
+	if d.Kind == "materialized view" && !d.Populated {
+		overview.addProperty("Populated", "no, refresh the view to query it")
+	}
+	if q := d.Sequence; q != nil {
+		overview.addProperty("Data type", q.Type)
+		overview.addProperty("Last value", q.LastValue)
+		overview.addProperty("Start", q.Start)
+		overview.addProperty("Increment", q.Increment)
+		overview.addProperty("Minimum", q.Min)
+		overview.addProperty("Maximum", q.Max)
+		overview.addProperty("Cache", q.Cache)
+		overview.addProperty("Cycle", strconv.FormatBool(q.Cycle))
+		overview.addProperty("Owned by", q.OwnedBy)
+	}
+
+	columns := describeSection{name: "Columns", header: []string{"Column", "Type", "Collation", "Nullable", "Default", "Comment"}}
+	for _, column := range d.Columns {
+		nullable := ""
+		if column.NotNull {
+			nullable = "not null"
+		}
+		columns.rows = append(columns.rows, []string{column.Name, column.Type, column.Collation, nullable, column.getDefault(), column.Comment})
+	}
+
+	indexes := describeSection{name: "Indexes", header: []string{"Index", "Definition", "Size"}}
+	for _, index := range d.Indexes {
+		definition := index.Definition
+		if !index.Valid {
+			definition += " INVALID"
+		}
+		indexes.rows = append(indexes.rows, []string{index.Name, definition, index.Size})
+	}
+
+	constraints := describeSection{name: "Constraints", header: []string{"Constraint", "Type", "Definition"}}
+	for _, constraint := range d.Constraints {
+		constraints.rows = append(constraints.rows, []string{constraint.Name, constraint.Type, constraint.Definition})
+	}
+
+	triggers := describeSection{name: "Triggers", header: []string{"Trigger", "Enabled", "Definition"}}
+	for _, trigger := range d.Triggers {
+		triggers.rows = append(triggers.rows, []string{trigger.Name, trigger.Enabled, trigger.Definition})
+	}
+
+	policies := describeSection{name: "Policies", header: []string{"Policy", "Type", "Command", "Roles", "Using", "With check"}}
+	for _, policy := range d.Policies {
+		policies.rows = append(policies.rows, []string{policy.Name, policy.Permissive, policy.Command, policy.Roles, policy.Using, policy.Check})
+	}
+
+	partitions := describeSection{name: "Partitions", header: []string{"Partition", "Bound", "Size"}}
+	for _, partition := range d.Partitions {
+		partitions.rows = append(partitions.rows, []string{partition.Name, partition.Bound, partition.Size})
+	}
+
+	sections := []describeSection{overview}
+	if d.Sequence == nil {
+		sections = append(sections, columns)
+	}
+	if d.Definition != "" {
+		sections = append(sections, describeSection{name: "Definition", text: strings.TrimRight(d.Definition, " \n")})
+	}
+	for _, section := range []describeSection{indexes, constraints, triggers, policies, partitions} {
+		if len(section.rows) > 0 {
+			sections = append(sections, section)
+		}
+	}
+	return append(sections, describeSection{name: "DDL", text: d.DDL()})
+}
@@ -0,0 +1,429 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/lib/pq"
+	"github.com/rivo/tview"
+
+	"github.com/rhariady/csql/pkg/session"
+)
+
+type changeKind int
+
+const (
+	changeUpdate changeKind = iota
+	changeInsert
+	changeDelete
+)
+
+// stagedChange is a change of a row of the table view, applied on commit.
+type stagedChange struct {
+	kind changeKind
+	// key is the primary key of the updated or deleted row, as SQL literals
+	// in the order of the key columns
+	key []string
+	// columns are the columns set by an update or an insert, in the order
+	// they were set, and values their new values, nil for NULL
+	columns []string
+	values  map[string]*string
+}
+
+func (c *stagedChange) setValue(column string, value *string) {
+	if !slices.Contains(c.columns, column) {
+		c.columns = append(c.columns, column)
+	}
+	c.values[column] = value
+}
+
+// tableEdits is the staged changes of the rows of a table, which are applied
+// in a single transaction. Rows are identified by their primary key.
+type tableEdits struct {
+	schema     string
+	table      string
+	columns    []tableColumn
+	keyColumns []string
+	changes    []*stagedChange
+}
+
+func newTableEdits(schema string, table string, columns []tableColumn) (*tableEdits, error) {
+	e := &tableEdits{
+		schema:  schema,
+		table:   table,
+		columns: columns,
+	}
+	for _, column := range columns {
+		if column.PrimaryKey {
+			e.keyColumns = append(e.keyColumns, column.Name)
+		}
+	}
+	if len(e.keyColumns) == 0 {
+		return nil, fmt.Errorf("the table %s has no primary key: its rows cannot be edited", qualifiedName(schema, table))
+	}
+	return e, nil
+}
+
+// rowKey returns the primary key of a row of the grid. ok is false when a
+// column of the key is not shown.
+func (e *tableEdits) rowKey(columns []resultColumn, record resultRow) (key []string, ok bool) {
+	for _, keyColumn := range e.keyColumns {
+		idx := slices.IndexFunc(columns, func(column resultColumn) bool { return column.Name == keyColumn })
+		if idx < 0 {
+			return nil, false
+		}
+		key = append(key, columns[idx].exportLiteral(record[idx]))
+	}
+	return key, true
+}
+
+func (e *tableEdits) find(kind changeKind, key []string) *stagedChange {
+	for _, change := range e.changes {
+		if change.kind == kind && slices.Equal(change.key, key) {
+			return change
+		}
+	}
+	return nil
+}
+
+// SetValue stages a new value of a column of a row, nil for NULL. A row
+// staged for deletion cannot be updated.
+func (e *tableEdits) SetValue(key []string, column string, value *string) error {
+	if e.find(changeDelete, key) != nil {
+		return errors.New("the row is staged for deletion")
+	}
+
+	change := e.find(changeUpdate, key)
+	if change == nil {
+		change = &stagedChange{kind: changeUpdate, key: key, values: make(map[string]*string)}
+		e.changes = append(e.changes, change)
+	}
+	change.setValue(column, value)
+	return nil
+}
+
+// ToggleDelete stages the deletion of a row, replacing its update if any, or
+// unstages it.
+func (e *tableEdits) ToggleDelete(key []string) {
+	deleted := e.isDeleted(key)
+	e.Unstage(key)
+	if deleted {
+		return
+	}
+	e.changes = append(e.changes, &stagedChange{kind: changeDelete, key: key})
+}
+
+// Unstage removes the update or the deletion of a row. It reports whether the
+// row was changed.
+func (e *tableEdits) Unstage(key []string) bool {
+	count := len(e.changes)
+	e.changes = slices.DeleteFunc(e.changes, func(change *stagedChange) bool {
+		return change.kind != changeInsert && slices.Equal(change.key, key)
+	})
+	return len(e.changes) < count
+}
+
+// Insert stages a new row. The columns without value get their default.
+func (e *tableEdits) Insert(columns []string, values map[string]*string) {
+	change := &stagedChange{kind: changeInsert, values: make(map[string]*string)}
+	for _, column := range columns {
+		change.setValue(column, values[column])
+	}
+	e.changes = append(e.changes, change)
+}
+
+// getValue returns the staged value of a column of a row.
+func (e *tableEdits) getValue(key []string, column string) (value *string, ok bool) {
+	if change := e.find(changeUpdate, key); change != nil {
+		value, ok = change.values[column]
+	}
+	return
+}
+
+func (e *tableEdits) isDeleted(key []string) bool {
+	return e.find(changeDelete, key) != nil
+}
+
+// Statements returns the statements applying the changes, in the order they
+// were staged.
+func (e *tableEdits) Statements() []string {
+	name := qualifiedName(e.schema, e.table)
+
+	var statements []string
+	for _, change := range e.changes {
+		var columns, values []string
+		for _, column := range change.columns {
+			columns = append(columns, pq.QuoteIdentifier(column))
+			values = append(values, valueLiteral(change.values[column]))
+		}
+
+		switch change.kind {
+		case changeInsert:
+			if len(columns) == 0 {
+				statements = append(statements, fmt.Sprintf("INSERT INTO %s DEFAULT VALUES", name))
+				continue
+			}
+			statements = append(statements, fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", name, strings.Join(columns, ", "), strings.Join(values, ", ")))
+		case changeUpdate:
+			var assignments []string
+			for i := range columns {
+				assignments = append(assignments, fmt.Sprintf("%s = %s", columns[i], values[i]))
+			}
+			statements = append(statements, fmt.Sprintf("UPDATE %s SET %s WHERE %s", name, strings.Join(assignments, ", "), e.keyCondition(change.key)))
+		case changeDelete:
+			statements = append(statements, fmt.Sprintf("DELETE FROM %s WHERE %s", name, e.keyCondition(change.key)))
+		}
+	}
+	return statements
+}
+
+func (e *tableEdits) keyCondition(key []string) string {
+	var conditions []string
+	for i, column := range e.keyColumns {
+		conditions = append(conditions, fmt.Sprintf("%s = %s", pq.QuoteIdentifier(column), key[i]))
+	}
+	return strings.Join(conditions, " AND ")
+}
+
+// valueLiteral returns the SQL literal of a value typed by the user, which is
+// converted to the type of its column by PostgreSQL.
+func valueLiteral(value *string) string {
+	if value == nil {
+		return "NULL"
+	}
+	return pq.QuoteLiteral(*value)
+}
+
+// applyEdits executes the statements of staged changes in one transaction.
+// Each statement must change exactly one row, otherwise the transaction is
+// rolled back: the row may have been changed or deleted in the meantime.
+func (a *PostgreSQLAdapter) applyEdits(ctx context.Context, s *session.Session, statements []string) error {
+	tx, err := a.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, statement := range statements {
+		start := time.Now()
+		var rows int64
+		res, err := tx.ExecContext(ctx, statement)
+		if err == nil {
+			rows, err = res.RowsAffected()
+		}
+		if err == nil && rows != 1 {
+			err = fmt.Errorf("%d rows changed instead of 1 by:\n%s", rows, statement)
+		}
+		a.audit(s, "table_edit", statement, start, rows, err)
+		if err != nil {
+			return err
+		}
+	}
+
+	start := time.Now()
+	err = tx.Commit()
+	a.audit(s, "table_edit", "COMMIT", start, 0, err)
+	return err
+}
+
+// CellEditModal edits the value of a cell of the table view, nil for NULL.
+// Nothing is staged when the value is not changed.
+type CellEditModal struct {
+	*PostgreSQLAdapter
+	column string
+	value  *string
+	stage  func(value *string)
+}
+
+func NewCellEditModal(adapter *PostgreSQLAdapter, column string, value *string, stage func(value *string)) *CellEditModal {
+	return &CellEditModal{
+		PostgreSQLAdapter: adapter,
+		column:            column,
+		value:             value,
+		stage:             stage,
+	}
+}
+
+func (c *CellEditModal) GetTitle() string {
+	return fmt.Sprintf("Edit %s", c.column)
+}
+
+func (c *CellEditModal) GetContent(s *session.Session) tview.Primitive {
+	var form *tview.Form
+
+	text := ""
+	if c.value != nil {
+		text = *c.value
+	}
+
+	form = tview.NewForm().
+		AddTextArea("Value", text, 0, 0, 0, nil).
+		AddButton("Stage", func() {
+			value := form.GetFormItemByLabel("Value").(*tview.TextArea).GetText()
+			s.CloseModal()
+			// An unchanged value is not staged, so that an empty value keeps a
+			// NULL cell NULL
+			if value == text {
+				return
+			}
+			c.stage(&value)
+		}).
+		AddButton("Set NULL", func() {
+			s.CloseModal()
+			c.stage(nil)
+		}).
+		AddButton("Cancel", func() {
+			s.CloseModal()
+		})
+
+	if c.value == nil {
+		form.GetFormItemByLabel("Value").(*tview.TextArea).SetPlaceholder("NULL")
+	}
+
+	form.SetFieldBackgroundColor(tcell.ColorGray)
+	form.SetButtonBackgroundColor(tcell.ColorDarkGray)
+
+	return form
+}
+
+func (c *CellEditModal) GetKeyBindings() (keybindings []*session.KeyBinding) {
+	return
+}
+
+func (c *CellEditModal) GetInfo() (info []session.Info) {
+	return
+}
+
+// InsertRowModal prompts for the values of a new row of the table view.
+// Columns left empty get their default value.
+type InsertRowModal struct {
+	*PostgreSQLAdapter
+	columns []tableColumn
+	stage   func(columns []string, values map[string]*string)
+}
+
+func NewInsertRowModal(adapter *PostgreSQLAdapter, columns []tableColumn, stage func(columns []string, values map[string]*string)) *InsertRowModal {
+	return &InsertRowModal{
+		PostgreSQLAdapter: adapter,
+		columns:           columns,
+		stage:             stage,
+	}
+}
+
+func (i *InsertRowModal) GetTitle() string {
+	return "Insert Row"
+}
+
+func (i *InsertRowModal) GetContent(s *session.Session) tview.Primitive {
+	form := tview.NewForm()
+
+	for _, column := range i.columns {
+		label := fmt.Sprintf("%s (%s)", column.Name, column.Type)
+		placeholder := column.Default
+		switch {
+		case column.Generated:
+			placeholder = "generated"
+		case placeholder == "" && !column.NotNull:
+			placeholder = "NULL"
+		}
+		form.AddInputField(label, "", 0, nil, nil)
+		form.GetFormItemByLabel(label).(*tview.InputField).SetPlaceholder(placeholder)
+	}
+
+	form.
+		AddButton("Stage", func() {
+			var columns []string
+			values := make(map[string]*string)
+			for _, column := range i.columns {
+				label := fmt.Sprintf("%s (%s)", column.Name, column.Type)
+				value := form.GetFormItemByLabel(label).(*tview.InputField).GetText()
+				if value == "" {
+					continue
+				}
+				columns = append(columns, column.Name)
+				values[column.Name] = &value
+			}
+
+			s.CloseModal()
+			i.stage(columns, values)
+		}).
+		AddButton("Cancel", func() {
+			s.CloseModal()
+		})
+
+	form.SetFieldBackgroundColor(tcell.ColorGray)
+	form.SetButtonBackgroundColor(tcell.ColorDarkGray)
+
+	return form
+}
+
+func (i *InsertRowModal) GetKeyBindings() (keybindings []*session.KeyBinding) {
+	return
+}
+
+func (i *InsertRowModal) GetInfo() (info []session.Info) {
+	return
+}
+
+// StagedChangesModal shows the statements of the staged changes, to commit or
+// discard them.
+type StagedChangesModal struct {
+	*PostgreSQLAdapter
+	statements []string
+	commit     func()
+	discard    func()
+}
+
+func NewStagedChangesModal(adapter *PostgreSQLAdapter, statements []string, commit func(), discard func()) *StagedChangesModal {
+	return &StagedChangesModal{
+		PostgreSQLAdapter: adapter,
+		statements:        statements,
+		commit:            commit,
+		discard:           discard,
+	}
+}
+
+func (c *StagedChangesModal) GetTitle() string {
+	return fmt.Sprintf("Staged Changes (%d)", len(c.statements))
+}
+
+func (c *StagedChangesModal) GetContent(s *session.Session) tview.Primitive {
+	preview := tview.NewTextView().
+		SetWrap(true).
+		SetText(strings.Join(c.statements, ";\n") + ";")
+	preview.SetBorder(true)
+
+	form := tview.NewForm().
+		AddButton("Commit", func() {
+			s.CloseModal()
+			c.commit()
+		}).
+		AddButton("Discard", func() {
+			s.CloseModal()
+			c.discard()
+		}).
+		AddButton("Cancel", func() {
+			s.CloseModal()
+		})
+	form.SetButtonBackgroundColor(tcell.ColorDarkGray)
+
+	layout := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(preview, 0, 1, false).
+		AddItem(form, 3, 0, true)
+
+	return layout
+}
+
+func (c *StagedChangesModal) GetKeyBindings() (keybindings []*session.KeyBinding) {
+	return
+}
+
+func (c *StagedChangesModal) GetInfo() (info []session.Info) {
+	return
+}
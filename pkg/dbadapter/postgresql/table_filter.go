@@ -0,0 +1,168 @@
+package postgresql
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"github.com/rhariady/csql/pkg/sqlutil"
+)
+
+// columnSort is a column of the ORDER BY clause of the table view.
+type columnSort struct {
+	column     string
+	descending bool
+}
+
+// tableFilter is the selection of the rows and columns of a table shown in the
+// table view, from which its query is generated. Identifiers are quoted, and
+// the WHERE clause is used as typed.
+type tableFilter struct {
+	// columns are the columns of the table in their display order, once
+	// known, and hidden are the ones not shown. moved is set once the order
+	// differs from the order of the table.
+	columns []string
+	hidden  map[string]bool
+	moved   bool
+	where   string
+	sort    []columnSort
+	// limit is the size of the pages, 0 showing all the rows
+	limit  int
+	offset int
+}
+
+// clone returns a copy of the filter which can be changed independently.
+func (f tableFilter) clone() tableFilter {
+	f.columns = slices.Clone(f.columns)
+	f.hidden = maps.Clone(f.hidden)
+	f.sort = slices.Clone(f.sort)
+	return f
+}
+
+// Query returns the SELECT statement of the filter for a table.
+func (f tableFilter) Query(schema string, table string) string {
+	selectList := "*"
+	if f.moved || len(f.hidden) > 0 {
+		var columns []string
+		for _, column := range f.visibleColumns() {
+			columns = append(columns, pq.QuoteIdentifier(column))
+		}
+		selectList = strings.Join(columns, ", ")
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s", selectList, qualifiedName(schema, table))
+	if f.where != "" {
+		// The line break ends a trailing comment of the clause
+		query += fmt.Sprintf(" WHERE (%s\n)", f.where)
+	}
+	if len(f.sort) > 0 {
+		var orderBy []string
+		for _, sort := range f.sort {
+			direction := "ASC"
+			if sort.descending {
+				direction = "DESC"
+			}
+			orderBy = append(orderBy, fmt.Sprintf("%s %s", pq.QuoteIdentifier(sort.column), direction))
+		}
+		query += " ORDER BY " + strings.Join(orderBy, ", ")
+	}
+	if f.limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", f.limit)
+	}
+	if f.offset > 0 {
+		query += fmt.Sprintf(" OFFSET %d", f.offset)
+	}
+	return query
+}
+
+// SetWhere sets the WHERE clause typed by the user, which must be a single
+// condition: a semicolon could end the query and run another statement.
+func (f *tableFilter) SetWhere(where string) error {
+	for _, token := range sqlutil.SignificantTokens(where) {
+		if token.Text == ";" {
+			return errors.New("the WHERE clause cannot contain a semicolon")
+		}
+	}
+	f.where = where
+	return nil
+}
+
+func (f tableFilter) visibleColumns() []string {
+	var columns []string
+	for _, column := range f.columns {
+		if !f.hidden[column] {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// ToggleSort cycles the sort of a column: ascending, descending, and unsorted.
+// A column sorted for the first time is sorted after the other ones.
+func (f *tableFilter) ToggleSort(column string) {
+	idx := slices.IndexFunc(f.sort, func(sort columnSort) bool { return sort.column == column })
+	switch {
+	case idx < 0:
+		f.sort = append(f.sort, columnSort{column: column})
+	case !f.sort[idx].descending:
+		f.sort[idx].descending = true
+	default:
+		f.sort = slices.Delete(f.sort, idx, idx+1)
+	}
+}
+
+// Hide hides a column. The last visible column cannot be hidden.
+func (f *tableFilter) Hide(column string) bool {
+	if len(f.visibleColumns()) <= 1 {
+		return false
+	}
+	if f.hidden == nil {
+		f.hidden = make(map[string]bool)
+	}
+	f.hidden[column] = true
+	return true
+}
+
+// ShowAll shows the hidden columns again.
+func (f *tableFilter) ShowAll() {
+	f.hidden = nil
+}
+
+// Move moves a column before the previous visible column, or after the next
+// one, depending on the sign of step.
+func (f *tableFilter) Move(column string, step int) bool {
+	visible := f.visibleColumns()
+	idx := slices.Index(visible, column)
+	target := idx + step
+	if idx < 0 || target < 0 || target >= len(visible) {
+		return false
+	}
+
+	// Swap the columns in the full list, keeping the hidden ones in place
+	from, to := slices.Index(f.columns, column), slices.Index(f.columns, visible[target])
+	f.columns[from], f.columns[to] = f.columns[to], f.columns[from]
+	f.moved = true
+	return true
+}
+
+// GetMarks returns the sort indicators of the sorted columns, numbered when
+// several columns are sorted.
+func (f tableFilter) GetMarks() map[string]string {
+	marks := make(map[string]string)
+	for i, sort := range f.sort {
+		mark := "▲"
+		if sort.descending {
+			mark = "▼"
+		}
+		if len(f.sort) > 1 {
+			mark += strconv.Itoa(i + 1)
+		}
+		marks[sort.column] = mark
+	}
+	return marks
+}
@@ -1,7 +1,9 @@
 package postgresql
 
 import (
+	"context"
 	"fmt"
+	"strings"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
@@ -14,6 +16,8 @@ type TableRecord struct {
 	Schema string
 	Type   string
 	Owner  string
+	// Oid identifies the functions, types and extensions
+	Oid uint32
 }
 
 type TableList struct {
@@ -27,61 +31,107 @@ func NewTableList(adapter *PostgreSQLAdapter) *TableList {
 	}
 }
 func (tl *TableList) GetTitle() string {
-	return "Tables"
+	return string(tl.getObjectKind())
 }
 
-func (tl *TableList) GetContent(session *session.Session) tview.Primitive {
+func (tl *TableList) GetContent(s *session.Session) tview.Primitive {
 	tableTable := tview.NewTable().
 		SetBorders(false).
 		SetSelectable(true, false)
 
+	filterTable := session.NewFilterTable(tableTable)
+
+	kind := tl.getObjectKind()
+
 	go func() {
-		session.ShowMessageAsync("Loading tables", false)
+		s.ShowMessageAsync(fmt.Sprintf("Loading %s", strings.ToLower(string(kind))), false)
 
-		tables, err := tl.listTables()
-		session.CloseMessageAsync()
+		tables, err := tl.listObjects(context.Background(), kind)
+		s.CloseMessageAsync()
 
 		if err != nil {
-			session.ShowMessageAsync(fmt.Sprintf("Error:\n%s", err), true)
+			s.ShowMessageAsync(fmt.Sprintf("Error:\n%s", err), true)
 			fmt.Println(err)
 		}
 
-		session.App.QueueUpdateDraw(func() {
-			tableTable.SetCell(0, 0, tview.NewTableCell("Schema").SetSelectable(false).SetExpansion(1))
-			tableTable.SetCell(0, 1, tview.NewTableCell("Name").SetSelectable(false).SetExpansion(1))
-			tableTable.SetCell(0, 2, tview.NewTableCell("Type").SetSelectable(false).SetExpansion(1))
-			tableTable.SetCell(0, 3, tview.NewTableCell("Owner").SetSelectable(false).SetExpansion(1))
-
-			for i, table := range tables {
-				tableTable.SetCell(i+1, 0, tview.NewTableCell(table.Schema))
-				tableTable.SetCell(i+1, 1, tview.NewTableCell(table.Name))
-				tableTable.SetCell(i+1, 2, tview.NewTableCell(table.Type))
-				tableTable.SetCell(i+1, 3, tview.NewTableCell(table.Owner))
-
+		s.App.QueueUpdateDraw(func() {
+			filterTable.SetHeader(
+				tview.NewTableCell("Schema").SetExpansion(1),
+				tview.NewTableCell("Name").SetExpansion(1),
+				tview.NewTableCell(kind.getTypeHeader()).SetExpansion(1),
+				tview.NewTableCell("Owner").SetExpansion(1),
+			)
+
+			var rows [][]*tview.TableCell
+			for _, table := range tables {
+				rows = append(rows, []*tview.TableCell{
+					tview.NewTableCell(table.Schema).SetReference(table),
+					tview.NewTableCell(table.Name),
+					tview.NewTableCell(table.Type),
+					tview.NewTableCell(table.Owner),
+				})
 			}
+			filterTable.SetRows(rows)
 		})
 
 	}()
 
-	tableTable.SetSelectedFunc(func(row int, column int) {
+	getRecord := func() (TableRecord, bool) {
+		row, _ := tableTable.GetSelection()
 		if row == 0 { // Skip header
+			return TableRecord{}, false
+		}
+		record, ok := tableTable.GetCell(row, 0).GetReference().(TableRecord)
+		return record, ok
+	}
+
+	tableTable.SetSelectedFunc(func(row int, column int) {
+		record, ok := getRecord()
+		if !ok {
+			return
+		}
+		// Only the rows of relations can be queried, and sequences have a
+		// single row better shown by their description
+		if kind != objectRelations || record.Type == "sequence" {
+			s.SetView(NewObjectDescription(tl.PostgreSQLAdapter, kind, record))
 			return
 		}
-		tableName := tableTable.GetCell(row, 1).Text
-		tableQuery := NewTableQuery(tl.PostgreSQLAdapter, tableName)
-		session.SetView(tableQuery)
+		tableQuery := NewTableQuery(tl.PostgreSQLAdapter, record.Schema, record.Name)
+		s.SetView(tableQuery)
 	})
 
 	tableTable.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		return tl.InputCapture(session, event)
+		switch event.Rune() {
+		case 'f':
+			filterTable.Show(s)
+			return nil
+		case 't':
+			s.ShowModal(NewObjectKindModal(tl.PostgreSQLAdapter))
+			return nil
+		case 'D':
+			if record, ok := getRecord(); ok {
+				s.SetView(NewObjectDescription(tl.PostgreSQLAdapter, kind, record))
+			}
+			return nil
+		case 'R':
+			if record, ok := getRecord(); ok && record.Type == "materialized view" {
+				tl.refreshMaterializedView(s, record.Schema, record.Name, func() {})
+			}
+			return nil
+		}
+		return tl.InputCapture(s, event)
 	})
 
-	return tableTable
+	return filterTable
 }
 
 func (i *TableList) GetKeyBindings() (keybindings []*session.KeyBinding) {
 	keybindings = []*session.KeyBinding{
-		session.NewKeyBinding("<enter>", "Query table"),
+		session.NewKeyBinding("<enter>", "Query table/describe object"),
+		session.NewKeyBinding("[D]", "Describe object"),
+		session.NewKeyBinding("[R]", "Refresh materialized view"),
+		session.NewKeyBinding("[t]", "Switch object type"),
+		session.NewKeyBinding("[f]", "Filter objects"),
 	}
 
 	base_keybinding := i.PostgreSQLAdapter.GetKeyBindings()
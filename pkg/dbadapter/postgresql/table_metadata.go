@@ -0,0 +1,120 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// tableColumn is the definition of a column of a table.
+type tableColumn struct {
+	Name    string
+	Type    string
+	NotNull bool
+	// Default is the expression of the default value, if any
+	Default string
+	// Generated is set for identity and generated columns
+	Generated  bool
+	PrimaryKey bool
+}
+
+// qualifiedName returns the quoted name of a table in a schema.
+func qualifiedName(schema string, table string) string {
+	return fmt.Sprintf("%s.%s", pq.QuoteIdentifier(schema), pq.QuoteIdentifier(table))
+}
+
+// getTableColumns returns the columns of a table, in their order in the table.
+func (a *PostgreSQLAdapter) getTableColumns(ctx context.Context, schema string, table string) (columns []tableColumn, err error) {
+	rows, err := a.conn.QueryContext(ctx, `SELECT a.attname,
+pg_catalog.format_type(a.atttypid, a.atttypmod),
+a.attnotnull,
+COALESCE(pg_catalog.pg_get_expr(d.adbin, d.adrelid), ''),
+a.attidentity <> '' OR a.attgenerated <> '',
+EXISTS (SELECT 1 FROM pg_catalog.pg_index i
+    WHERE i.indrelid = a.attrelid AND i.indisprimary AND a.attnum = ANY(i.indkey))
+FROM pg_catalog.pg_attribute a
+    LEFT JOIN pg_catalog.pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+WHERE a.attrelid = $1::regclass
+    AND a.attnum > 0
+    AND NOT a.attisdropped
+ORDER BY a.attnum`, qualifiedName(schema, table))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		r_err := rows.Close()
+		if r_err != nil {
+			err = r_err
+		}
+	}()
+
+	for rows.Next() {
+		var column tableColumn
+		if err := rows.Scan(&column.Name, &column.Type, &column.NotNull, &column.Default, &column.Generated, &column.PrimaryKey); err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+	}
+
+	return columns, rows.Err()
+}
+
+// foreignKey is a foreign key constraint from the columns of a table to the
+// columns of the table it references.
+type foreignKey struct {
+	Name       string
+	Schema     string
+	Table      string
+	Columns    []string
+	RefSchema  string
+	RefTable   string
+	RefColumns []string
+}
+
+// getForeignKeys returns the foreign keys of a table, and the foreign keys of
+// the tables referencing it.
+func (a *PostgreSQLAdapter) getForeignKeys(ctx context.Context, schema string, table string) (references []foreignKey, referencedBy []foreignKey, err error) {
+	rows, err := a.conn.QueryContext(ctx, `SELECT c.conname,
+n.nspname, t.relname,
+ARRAY(SELECT a.attname FROM unnest(c.conkey) WITH ORDINALITY k(attnum, ord)
+    JOIN pg_catalog.pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = k.attnum ORDER BY k.ord),
+rn.nspname, rt.relname,
+ARRAY(SELECT a.attname FROM unnest(c.confkey) WITH ORDINALITY k(attnum, ord)
+    JOIN pg_catalog.pg_attribute a ON a.attrelid = c.confrelid AND a.attnum = k.attnum ORDER BY k.ord),
+c.conrelid = $1::regclass, c.confrelid = $1::regclass
+FROM pg_catalog.pg_constraint c
+    JOIN pg_catalog.pg_class t ON t.oid = c.conrelid
+    JOIN pg_catalog.pg_namespace n ON n.oid = t.relnamespace
+    JOIN pg_catalog.pg_class rt ON rt.oid = c.confrelid
+    JOIN pg_catalog.pg_namespace rn ON rn.oid = rt.relnamespace
+WHERE c.contype = 'f'
+    AND (c.conrelid = $1::regclass OR c.confrelid = $1::regclass)
+ORDER BY n.nspname, t.relname, c.conname`, qualifiedName(schema, table))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		r_err := rows.Close()
+		if r_err != nil {
+			err = r_err
+		}
+	}()
+
+	for rows.Next() {
+		var key foreignKey
+		var outgoing, incoming bool
+		if err := rows.Scan(&key.Name, &key.Schema, &key.Table, pq.Array(&key.Columns), &key.RefSchema, &key.RefTable, pq.Array(&key.RefColumns), &outgoing, &incoming); err != nil {
+			return nil, nil, err
+		}
+		// A self-referencing key is in both lists
+		if outgoing {
+			references = append(references, key)
+		}
+		if incoming {
+			referencedBy = append(referencedBy, key)
+		}
+	}
+
+	return references, referencedBy, rows.Err()
+}
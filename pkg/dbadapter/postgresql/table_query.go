@@ -2,9 +2,11 @@ package postgresql
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
-	_ "strings"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
@@ -22,12 +24,34 @@ type TableQueryRecord struct {
 type TableQuery struct {
 	*PostgreSQLAdapter
 
-	table string
+	schema string
+	table  string
+	grid   *ResultGrid
+	// filter is the selection of the rows and columns shown
+	filter tableFilter
+	// edits is the staged changes of the edit mode, nil outside of it
+	edits *tableEdits
+	// back is the table view this one was opened from by following a foreign
+	// key, and selectedRow and selectedColumn the cell selected when the view
+	// was left, selected again when going back to it
+	back           *TableQuery
+	selectedRow    int
+	selectedColumn int
+	// references and referencedBy are the foreign keys of the table, and the
+	// ones referencing it, loaded on first use
+	references        []foreignKey
+	referencedBy      []foreignKey
+	foreignKeysLoaded bool
+
+	resultTable *tview.Table
+	whereInput  *tview.InputField
+	layout      *tview.Flex
 }
 
-func NewTableQuery(adapter *PostgreSQLAdapter, table string) *TableQuery {
+func NewTableQuery(adapter *PostgreSQLAdapter, schema string, table string) *TableQuery {
 	return &TableQuery{
 		PostgreSQLAdapter: adapter,
+		schema:            schema,
 		table:             table,
 	}
 }
@@ -39,28 +63,48 @@ func (tq *TableQuery) GetTitle() string {
 func (tq *TableQuery) GetContent(session *session.Session) tview.Primitive {
 	queryResultTable := tview.NewTable().
 		SetBorders(true).
-		SetSelectable(false, false).
+		SetSelectable(true, true).
 		SetFixed(1, 0)
+	queryResultTable.SetBorder(true)
+	tq.resultTable = queryResultTable
 
-	go func() {
-		rows, columns, err := queryTable(tq.conn, tq.table)
-		if err != nil {
-			session.ShowMessageAsync(fmt.Sprintf("Error: %s", err), true)
-		}
-		for idx, column := range columns {
-			queryResultTable.SetCell(0, idx, tview.NewTableCell(column).SetSelectable(false))
-		}
+	whereInput := tview.NewInputField().
+		SetLabel("WHERE ").
+		SetFieldBackgroundColor(tcell.ColorBlack)
+	tq.whereInput = whereInput
 
-		for i, row := range rows {
-			for j, column := range columns {
-				queryResultTable.SetCell(i+1, j, tview.NewTableCell(row[column]))
-			}
+	tq.layout = tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(whereInput, 0, 0, false).
+		AddItem(queryResultTable, 0, 1, true)
 
-			queryResultTable.ScrollToBeginning()
-			session.App.Draw()
+	whereInput.SetDoneFunc(func(key tcell.Key) {
+		switch key {
+		case tcell.KeyEnter:
+			filter := tq.filter.clone()
+			if err := filter.SetWhere(strings.TrimSpace(whereInput.GetText())); err != nil {
+				session.ShowMessage(fmt.Sprintf("Error: %s", err), true)
+				return
+			}
+			filter.offset = 0
+			tq.load(session, filter)
+		case tcell.KeyEsc:
+			whereInput.SetText(tq.filter.where)
 		}
+		session.App.SetFocus(queryResultTable)
+		tq.updateWhereInput()
+	})
 
-	}()
+	tq.load(session, tq.filter)
+
+	queryResultTable.SetSelectedFunc(func(row, column int) {
+		if tq.grid != nil && tq.edits != nil {
+			tq.editCell(session, row-1, column)
+		} else if tq.grid != nil {
+			inspector := NewRecordInspector(tq.PostgreSQLAdapter, tq.grid, row-1, column)
+			session.ShowLargeModal(inspector)
+		}
+	})
 
 	queryResultTable.SetDoneFunc(func(key tcell.Key) {
 		if key == tcell.KeyEsc {
@@ -70,16 +114,283 @@ func (tq *TableQuery) GetContent(session *session.Session) tview.Primitive {
 	})
 
 	queryResultTable.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if copyFromGrid(session, tq.grid, queryResultTable, event.Rune()) {
+			return nil
+		}
+		if event.Rune() == 'e' && tq.grid != nil {
+			exportModal := NewExportModal(tq.PostgreSQLAdapter, tq.grid.GetQuery(), qualifiedName(tq.schema, tq.table), nil)
+			session.ShowModal(exportModal)
+			return nil
+		}
+
+		if tq.edits != nil {
+			switch {
+			case event.Rune() == 'a':
+				tq.insertRow(session)
+				return nil
+			case event.Rune() == 'D':
+				tq.stageRow(session, tq.edits.ToggleDelete)
+				return nil
+			case event.Rune() == 'u':
+				tq.stageRow(session, func(key []string) { tq.edits.Unstage(key) })
+				return nil
+			case event.Key() == tcell.KeyCtrlS:
+				tq.reviewChanges(session)
+				return nil
+			}
+		}
+
+		switch event.Rune() {
+		case 'f':
+			tq.followReference(session)
+			return nil
+		case 'F':
+			tq.showReferencingRows(session)
+			return nil
+		case 'b':
+			if tq.back != nil {
+				session.SetView(tq.back)
+			}
+			return nil
+		case 'E':
+			tq.toggleEditMode(session)
+			return nil
+		case 'w':
+			tq.layout.ResizeItem(whereInput, 1, 0)
+			session.App.SetFocus(whereInput)
+			return nil
+		case 'v':
+			queryEditor := NewQueryEditor(tq.PostgreSQLAdapter, tq.filter.Query(tq.schema, tq.table))
+			session.SetView(queryEditor)
+			return nil
+		case '[', ']':
+			tq.changePage(session, event.Rune() == ']')
+			return nil
+		case 'o', '-', '+', '<', '>':
+			tq.changeColumns(session, event.Rune())
+			return nil
+		}
 		return tq.InputCapture(session, event)
 	})
 
-	return queryResultTable
+	return tq.layout
+}
+
+// load runs the query of a filter and shows its rows. The filter becomes the
+// filter of the view once its query succeeded.
+func (tq *TableQuery) load(session *session.Session, filter tableFilter) {
+	ctx, cancel := context.WithCancel(context.Background())
+	session.ShowProgressAsync("Loading table...", cancel)
+	go func() {
+		defer cancel()
+
+		query := filter.Query(tq.schema, tq.table)
+		start := time.Now()
+		grid, err := OpenResultGrid(ctx, tq.conn, query)
+		var rows int64
+		if grid != nil {
+			rows = int64(grid.GetLoadedRowCount())
+		}
+		tq.audit(session, "table_query", query, start, rows, err)
+		session.CloseProgressAsync()
+		if ctx.Err() == context.Canceled {
+			session.ShowMessageAsync("Query cancelled", true)
+			return
+		}
+		if err != nil {
+			session.ShowMessageAsync(fmt.Sprintf("Error: %s", err), true)
+			return
+		}
+
+		session.App.QueueUpdateDraw(func() {
+			// The first query selects all the columns of the table
+			if filter.columns == nil {
+				filter.columns = grid.GetColumns()
+			}
+			tq.filter = filter
+			tq.setGrid(session, grid)
+		})
+	}()
+}
+
+// setGrid shows new rows, releasing the previous ones. The selected column is
+// kept when it is still shown.
+func (tq *TableQuery) setGrid(session *session.Session, grid *ResultGrid) {
+	table := tq.resultTable
+
+	selected := ""
+	if tq.grid != nil {
+		_, column := table.GetSelection()
+		if columns := tq.grid.GetColumns(); column >= 0 && column < len(columns) {
+			selected = columns[column]
+		}
+		tq.grid.Close()
+	}
+
+	tq.grid = grid
+	grid.SetColumnMarks(tq.filter.GetMarks())
+	grid.SetDecorateFunc(tq.decorateCell)
+	grid.SetChangedFunc(func() {
+		session.App.QueueUpdateDraw(func() {
+			table.SetTitle(tq.getStatus(grid))
+		})
+	})
+	table.SetContent(grid)
+	table.SetTitle(tq.getStatus(grid))
+	table.ScrollToBeginning()
+
+	column := max(slices.Index(grid.GetColumns(), selected), 0)
+	row := 1
+	if tq.selectedRow > 0 {
+		// Coming back to the view, the rows are loaded again
+		row = max(min(tq.selectedRow, grid.GetLoadedRowCount()), 1)
+		column = tq.selectedColumn
+		tq.selectedRow = 0
+	}
+	table.Select(row, column)
+
+	tq.whereInput.SetText(tq.filter.where)
+	tq.updateWhereInput()
+}
+
+// getStatus describes the rows shown, with the position of the page.
+func (tq *TableQuery) getStatus(grid *ResultGrid) string {
+	status := grid.GetStatus()
+	if tq.filter.limit > 0 {
+		status = fmt.Sprintf("%s from row %d", status, tq.filter.offset+1)
+	}
+	if tq.edits != nil {
+		status = fmt.Sprintf("%s, edit mode: %d staged changes", status, len(tq.edits.changes))
+	}
+	return status
+}
+
+// updateWhereInput shows the WHERE input while a WHERE clause is applied.
+func (tq *TableQuery) updateWhereInput() {
+	height := 0
+	if tq.filter.where != "" || tq.whereInput.HasFocus() {
+		height = 1
+	}
+	tq.layout.ResizeItem(tq.whereInput, height, 0)
+}
+
+// changePage goes to the next or the previous page of rows.
+func (tq *TableQuery) changePage(session *session.Session, next bool) {
+	if tq.filter.limit == 0 {
+		session.ShowNotice("Set the size of the pages with the limit command")
+		return
+	}
+
+	filter := tq.filter.clone()
+	if next {
+		if tq.grid != nil && tq.grid.IsDone() && tq.grid.GetLoadedRowCount() < filter.limit {
+			return
+		}
+		filter.offset += filter.limit
+	} else {
+		if filter.offset == 0 {
+			return
+		}
+		filter.offset = max(filter.offset-filter.limit, 0)
+	}
+	tq.load(session, filter)
+}
+
+// changeColumns sorts, hides or moves the selected column, or shows the
+// hidden columns.
+func (tq *TableQuery) changeColumns(session *session.Session, action rune) {
+	if tq.grid == nil {
+		return
+	}
+	_, index := tq.resultTable.GetSelection()
+	columns := tq.grid.GetColumns()
+	if index < 0 || index >= len(columns) {
+		return
+	}
+	column := columns[index]
+
+	filter := tq.filter.clone()
+	switch action {
+	case 'o':
+		filter.ToggleSort(column)
+	case '-':
+		if !filter.Hide(column) {
+			return
+		}
+	case '+':
+		if len(filter.hidden) == 0 {
+			return
+		}
+		filter.ShowAll()
+	case '<', '>':
+		step := 1
+		if action == '<' {
+			step = -1
+		}
+		if !filter.Move(column, step) {
+			return
+		}
+	}
+	tq.load(session, filter)
+}
+
+// ExecuteCommand handles the limit command, and the commands of the adapter.
+func (tq *TableQuery) ExecuteCommand(s *session.Session, command string) error {
+	name, args, _ := strings.Cut(strings.TrimSpace(command), " ")
+	if name != "limit" {
+		return tq.PostgreSQLAdapter.ExecuteCommand(s, command)
+	}
+
+	limit := 0
+	if args = strings.TrimSpace(args); args != "all" {
+		var err error
+		limit, err = strconv.Atoi(args)
+		if err != nil || limit < 0 {
+			return fmt.Errorf("usage: limit <rows>|all")
+		}
+	}
+
+	filter := tq.filter.clone()
+	filter.limit = limit
+	filter.offset = 0
+	tq.load(s, filter)
+	return nil
 }
 
 func (i *TableQuery) GetKeyBindings() (keybindings []*session.KeyBinding) {
+	if i.edits != nil {
+		keybindings = []*session.KeyBinding{
+			session.NewKeyBinding("<enter>", "Edit cell"),
+			session.NewKeyBinding("a", "Insert row"),
+			session.NewKeyBinding("D", "Delete/undelete row"),
+			session.NewKeyBinding("u", "Unstage row changes"),
+			session.NewKeyBinding("<ctrl-s>", "Review and commit changes"),
+			session.NewKeyBinding("E", "Leave edit mode"),
+		}
+		return append(keybindings, copyKeyBindings...)
+	}
+
 	keybindings = []*session.KeyBinding{
 		session.NewKeyBinding("<escape>", "Go back to table list"),
+		session.NewKeyBinding("<enter>", "Inspect selected row"),
+		session.NewKeyBinding("f", "Go to referenced row"),
+		session.NewKeyBinding("F", "Go to referencing rows"),
 	}
+	if i.back != nil {
+		keybindings = append(keybindings, session.NewKeyBinding("b", "Go back to previous table"))
+	}
+	keybindings = append(keybindings,
+		session.NewKeyBinding("E", "Edit mode"),
+		session.NewKeyBinding("w", "Filter rows (WHERE)"),
+		session.NewKeyBinding("o", "Sort by column"),
+		session.NewKeyBinding("- +", "Hide column/show all"),
+		session.NewKeyBinding("< >", "Move column"),
+		session.NewKeyBinding("[ ]", "Previous/next page"),
+		session.NewKeyBinding("v", "Open query in editor"),
+		session.NewKeyBinding("e", "Export rows"),
+		session.NewKeyBinding("<ctrl-c>", "Cancel running query"),
+	)
+	keybindings = append(keybindings, copyKeyBindings...)
 
 	base_keybinding := i.PostgreSQLAdapter.GetKeyBindings()
 	keybindings = append(keybindings, base_keybinding...)
@@ -87,48 +398,349 @@ func (i *TableQuery) GetKeyBindings() (keybindings []*session.KeyBinding) {
 	return
 }
 
-func queryTable(conn *sql.DB, tableName string) (results []map[string]string, columns []string, err error) {
-	query := fmt.Sprintf("SELECT * FROM %s LIMIT 100", tableName)
-	ctx := context.Background()
+// toggleEditMode enters the edit mode, in which the rows of a table with a
+// primary key can be changed, or leaves it after discarding the staged
+// changes.
+func (tq *TableQuery) toggleEditMode(s *session.Session) {
+	if tq.edits != nil {
+		leave := func() {
+			tq.edits = nil
+			tq.refreshRows()
+			s.RefreshHeader()
+		}
+		if len(tq.edits.changes) == 0 {
+			leave()
+			return
+		}
+		message := fmt.Sprintf("Discard the %d staged changes?", len(tq.edits.changes))
+		s.ShowAlert(message, func(s *session.Session) {
+			leave()
+		}, func(s *session.Session) {})
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.ShowProgress("Loading columns...", cancel)
+	go func() {
+		defer cancel()
+
+		columns, err := tq.getTableColumns(ctx, tq.schema, tq.table)
+		var edits *tableEdits
+		if err == nil {
+			edits, err = newTableEdits(tq.schema, tq.table, columns)
+		}
+		s.CloseProgressAsync()
+		if err != nil {
+			s.ShowMessageAsync(fmt.Sprintf("Error: %s", err), true)
+			return
+		}
+
+		s.App.QueueUpdateDraw(func() {
+			tq.edits = edits
+			tq.refreshRows()
+			s.RefreshHeader()
+		})
+	}()
+}
+
+// refreshRows draws the rows again, e.g. after a change was staged.
+func (tq *TableQuery) refreshRows() {
+	if tq.grid != nil {
+		tq.resultTable.SetTitle(tq.getStatus(tq.grid))
+	}
+}
+
+// decorateCell shows the staged changes in the grid: new values in yellow,
+// and deleted rows struck through in red.
+func (tq *TableQuery) decorateCell(columns []resultColumn, record resultRow, column int, cell *tview.TableCell) {
+	if tq.edits == nil || len(tq.edits.changes) == 0 {
+		return
+	}
+	key, ok := tq.edits.rowKey(columns, record)
+	if !ok {
+		return
+	}
+
+	if tq.edits.isDeleted(key) {
+		cell.SetTextColor(tcell.ColorRed).SetAttributes(tcell.AttrStrikeThrough)
+		return
+	}
+	if value, ok := tq.edits.getValue(key, columns[column].Name); ok {
+		if value == nil {
+			cell.SetText("NULL").SetAttributes(tcell.AttrItalic)
+		} else {
+			cell.SetText(tview.Escape(lineBreakReplacer.Replace(*value))).SetAttributes(tcell.AttrNone)
+		}
+		cell.SetTextColor(tcell.ColorYellow)
+	}
+}
+
+// getRowKey returns the primary key of a row of the grid, by index starting
+// at 0.
+func (tq *TableQuery) getRowKey(session *session.Session, row int) ([]string, bool) {
+	record, ok := tq.grid.GetRecord(row)
+	if !ok {
+		return nil, false
+	}
+	key, ok := tq.edits.rowKey(tq.grid.getResultColumns(), record)
+	if !ok {
+		session.ShowMessage("The columns of the primary key must be shown to edit the rows.", true)
+	}
+	return key, ok
+}
+
+// editCell prompts for the new value of a cell, and stages it.
+func (tq *TableQuery) editCell(session *session.Session, row int, column int) {
+	key, ok := tq.getRowKey(session, row)
+	if !ok {
+		return
+	}
+	record, _ := tq.grid.GetRecord(row)
+	columns := tq.grid.getResultColumns()
+	if column < 0 || column >= len(columns) {
+		return
+	}
 
-	rows, err := conn.QueryContext(ctx, query)
+	name := columns[column].Name
+	var value *string
+	if record[column] != nil {
+		text := columns[column].exportText(record[column])
+		value = &text
+	}
+	if staged, ok := tq.edits.getValue(key, name); ok {
+		value = staged
+	}
 
-	defer func() {
-		r_err := rows.Close()
-		if r_err != nil {
-			err = r_err
+	cellEditModal := NewCellEditModal(tq.PostgreSQLAdapter, name, value, func(value *string) {
+		if err := tq.edits.SetValue(key, name, value); err != nil {
+			session.ShowMessage(fmt.Sprintf("Error: %s", err), true)
 		}
+		tq.refreshRows()
+	})
+	session.ShowModal(cellEditModal)
+}
+
+// stageRow applies a change to the staged changes of the selected row.
+func (tq *TableQuery) stageRow(session *session.Session, change func(key []string)) {
+	if tq.grid == nil {
+		return
+	}
+	row, _ := tq.resultTable.GetSelection()
+	if key, ok := tq.getRowKey(session, row-1); ok {
+		change(key)
+		tq.refreshRows()
+	}
+}
+
+// insertRow prompts for the values of a new row, and stages it.
+func (tq *TableQuery) insertRow(session *session.Session) {
+	insertRowModal := NewInsertRowModal(tq.PostgreSQLAdapter, tq.edits.columns, func(columns []string, values map[string]*string) {
+		tq.edits.Insert(columns, values)
+		tq.refreshRows()
+	})
+	session.ShowLargeModal(insertRowModal)
+}
+
+// reviewChanges shows the statements of the staged changes, to commit them in
+// one transaction or discard them.
+func (tq *TableQuery) reviewChanges(session *session.Session) {
+	statements := tq.edits.Statements()
+	if len(statements) == 0 {
+		session.ShowNotice("No staged changes")
+		return
+	}
+
+	stagedChangesModal := NewStagedChangesModal(tq.PostgreSQLAdapter, statements, func() {
+		tq.confirmChanges(session, statements, func() {
+			tq.commitChanges(session, statements)
+		})
+	}, func() {
+		tq.edits.changes = nil
+		tq.refreshRows()
+	})
+	session.ShowLargeModal(stagedChangesModal)
+}
+
+// confirmChanges calls run once the staged changes are confirmed, with a
+// single confirmation for all of them on production instances.
+func (tq *TableQuery) confirmChanges(s *session.Session, statements []string, run func()) {
+	if !tq.instance.IsProduction() {
+		run()
+		return
+	}
+
+	message := fmt.Sprintf("%d changes will be committed on the production instance %s.\n\nDo you want to continue?", len(statements), tq.instance.Name)
+	s.ShowAlert(message, func(s *session.Session) {
+		run()
+	}, func(s *session.Session) {})
+}
+
+func (tq *TableQuery) commitChanges(session *session.Session, statements []string) {
+	ctx, cancel := context.WithCancel(context.Background())
+	session.ShowProgress("Committing changes...", cancel)
+	go func() {
+		defer cancel()
+
+		err := tq.applyEdits(ctx, session, statements)
+		session.CloseProgressAsync()
+
+		session.App.QueueUpdateDraw(func() {
+			if err != nil {
+				session.ShowMessage(fmt.Sprintf("Error, the changes were rolled back:\n%s", err), true)
+				return
+			}
+			if tq.edits != nil {
+				tq.edits.changes = nil
+			}
+			session.ShowNotice(fmt.Sprintf("%d changes committed", len(statements)))
+			tq.load(session, tq.filter)
+		})
 	}()
+}
+
+// GetLeaveWarning warns before leaving the view with staged changes.
+func (tq *TableQuery) GetLeaveWarning() string {
+	if tq.edits == nil || len(tq.edits.changes) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d staged changes are not committed.\nLeaving the table discards them.\n\nLeave anyway?", len(tq.edits.changes))
+}
+
+// Leave releases the cursor of the table rows, and discards the staged
+// changes. The selected cell is kept for going back to the view.
+func (tq *TableQuery) Leave() {
+	if tq.grid != nil {
+		tq.selectedRow, tq.selectedColumn = tq.resultTable.GetSelection()
+		tq.grid.Close()
+		tq.grid = nil
+	}
+	tq.edits = nil
+}
+
+// GetInfo shows the path of the foreign keys followed to the table.
+func (tq *TableQuery) GetInfo() (info []session.Info) {
+	info = tq.PostgreSQLAdapter.GetInfo()
+	if tq.back == nil {
+		return
+	}
+
+	var path []string
+	for view := tq; view != nil; view = view.back {
+		path = append([]string{fmt.Sprintf("%s.%s", view.schema, view.table)}, path...)
+	}
+	info = append(info, session.NewInfo("Path", strings.Join(path, " › ")))
+	return
+}
 
-	if err != nil {
-		return nil, nil, err
+// withForeignKeys calls a function with the foreign keys of the table, once
+// they are loaded.
+func (tq *TableQuery) withForeignKeys(s *session.Session, run func()) {
+	if tq.foreignKeysLoaded {
+		run()
+		return
 	}
 
-	var result []map[string]string
-	columns, err = rows.Columns()
-	if err != nil {
-		return nil, nil, err
+	ctx, cancel := context.WithCancel(context.Background())
+	s.ShowProgress("Loading foreign keys...", cancel)
+	go func() {
+		defer cancel()
+
+		references, referencedBy, err := tq.getForeignKeys(ctx, tq.schema, tq.table)
+		s.CloseProgressAsync()
+		if err != nil {
+			s.ShowMessageAsync(fmt.Sprintf("Error: %s", err), true)
+			return
+		}
+
+		s.App.QueueUpdateDraw(func() {
+			tq.references = references
+			tq.referencedBy = referencedBy
+			tq.foreignKeysLoaded = true
+			run()
+		})
+	}()
+}
+
+// getSelectedRecord returns the selected row of the grid, and the name of the
+// selected column.
+func (tq *TableQuery) getSelectedRecord() (record resultRow, column string, ok bool) {
+	if tq.grid == nil {
+		return nil, "", false
+	}
+	row, index := tq.resultTable.GetSelection()
+	record, ok = tq.grid.GetRecord(row - 1)
+	columns := tq.grid.GetColumns()
+	if !ok || index < 0 || index >= len(columns) {
+		return nil, "", false
 	}
+	return record, columns[index], true
+}
 
-	for rows.Next() {
-		x := make([]interface{}, len(columns))
-		scans := make([]string, len(columns))
-		row := make(map[string]string)
+// followReference opens the row of the parent table referenced by the foreign
+// key of the selected column.
+func (tq *TableQuery) followReference(s *session.Session) {
+	record, column, ok := tq.getSelectedRecord()
+	if !ok {
+		return
+	}
+	columns := tq.grid.getResultColumns()
 
-		for i := range scans {
-			x[i] = &scans[i]
+	tq.withForeignKeys(s, func() {
+		idx := slices.IndexFunc(tq.references, func(key foreignKey) bool {
+			return slices.Contains(key.Columns, column)
+		})
+		if idx < 0 {
+			s.ShowNotice(fmt.Sprintf("The column %s is not part of a foreign key", column))
+			return
 		}
-		err = rows.Scan(x...)
+
+		key := tq.references[idx]
+		condition, err := matchCondition(columns, record, key.Columns, key.RefColumns)
 		if err != nil {
-			return nil, nil, err
+			s.ShowMessage(fmt.Sprintf("Error: %s", err), true)
+			return
 		}
+		tq.openRelated(s, key.RefSchema, key.RefTable, condition)
+	})
+}
+
+// showReferencingRows opens the rows of a child table referencing the
+// selected row, choosing the foreign key when there are several.
+func (tq *TableQuery) showReferencingRows(s *session.Session) {
+	record, _, ok := tq.getSelectedRecord()
+	if !ok {
+		return
+	}
+	columns := tq.grid.getResultColumns()
 
-		for i, v := range scans {
-			row[columns[i]] = v
+	open := func(key foreignKey) {
+		condition, err := matchCondition(columns, record, key.RefColumns, key.Columns)
+		if err != nil {
+			s.ShowMessage(fmt.Sprintf("Error: %s", err), true)
+			return
 		}
-		result = append(result, row)
+		tq.openRelated(s, key.Schema, key.Table, condition)
 	}
 
-	return result, columns, nil
-	// return [][]string{[]string{"1", "y"}}
+	tq.withForeignKeys(s, func() {
+		switch len(tq.referencedBy) {
+		case 0:
+			s.ShowNotice(fmt.Sprintf("No foreign key references %s.%s", tq.schema, tq.table))
+		case 1:
+			open(tq.referencedBy[0])
+		default:
+			foreignKeyList := NewForeignKeyList(tq.PostgreSQLAdapter, "Referencing Tables", tq.referencedBy, open)
+			s.ShowLargeModal(foreignKeyList)
+		}
+	})
+}
+
+// openRelated opens the rows of a table matching a condition, in a view from
+// which b goes back to this one.
+func (tq *TableQuery) openRelated(s *session.Session, schema string, table string, condition string) {
+	related := NewTableQuery(tq.PostgreSQLAdapter, schema, table)
+	related.filter.where = condition
+	related.filter.limit = tq.filter.limit
+	related.back = tq
+	s.SetView(related)
 }
@@ -0,0 +1,100 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/rhariady/csql/pkg/sqlutil"
+)
+
+// The states of the transaction of the query editor
+const (
+	// transactionBegin is a transaction without statements yet
+	transactionBegin = "BEGIN"
+	// transactionActive is a transaction in which statements were executed
+	transactionActive = "IN-TX"
+	// transactionFailed is a transaction aborted by an error, which can only
+	// be rolled back
+	transactionFailed = "FAILED"
+)
+
+// editorTransaction is the explicit transaction of the query editor. It holds
+// a connection dedicated to the editor, on which a new transaction is started
+// each time the previous one is committed or rolled back.
+type editorTransaction struct {
+	conn  *sql.Conn
+	tx    *sql.Tx
+	state string
+}
+
+func beginTransaction(db *sql.DB) (*editorTransaction, error) {
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	t := &editorTransaction{conn: conn}
+	if err := t.begin(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return t, nil
+}
+
+func (t *editorTransaction) begin() error {
+	// The transaction must outlive the contexts of the statements
+	tx, err := t.conn.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	t.tx = tx
+	t.state = transactionBegin
+	return nil
+}
+
+// IsPending reports whether statements were executed in the transaction.
+func (t *editorTransaction) IsPending() bool {
+	return t.state != transactionBegin
+}
+
+// Commit commits the transaction. The next one is started with begin.
+func (t *editorTransaction) Commit() error {
+	return t.tx.Commit()
+}
+
+// Rollback rolls the transaction back. The next one is started with begin.
+func (t *editorTransaction) Rollback() error {
+	return t.tx.Rollback()
+}
+
+// Close rolls the transaction back and releases the connection. It returns
+// the error of the rollback.
+func (t *editorTransaction) Close() error {
+	err := t.tx.Rollback()
+	_ = t.conn.Close()
+	return err
+}
+
+// isTransactionControl reports whether a statement starts or ends a
+// transaction, which is done with the keys of the transaction mode instead.
+// Savepoints are allowed.
+func isTransactionControl(statement string) bool {
+	tokens := sqlutil.SignificantTokens(statement)
+	if len(tokens) == 0 || tokens[0].Type != sqlutil.Keyword {
+		return false
+	}
+
+	switch strings.ToUpper(tokens[0].Text) {
+	case "BEGIN", "START", "COMMIT", "END", "ABORT":
+		return true
+	case "ROLLBACK":
+		for _, token := range tokens[1:] {
+			if token.IsKeyword("TO") {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
@@ -3,6 +3,7 @@ package postgresql
 import (
 	"fmt"
 
+	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 
 	"github.com/rhariady/csql/pkg/session"
@@ -22,41 +23,60 @@ func (u *RoleList) GetTitle() string {
 	return "Roles"
 }
 
-func (u *RoleList) GetContent(session *session.Session) tview.Primitive {
+func (u *RoleList) GetContent(s *session.Session) tview.Primitive {
 	table := tview.NewTable().
 		SetBorders(false).
 		SetSelectable(true, false)
 
+	filterTable := session.NewFilterTable(table)
+
 	headers := []string{"Role name", "Attributes", "Member of", "Description"}
-	for i, header := range headers {
-		table.SetCell(0, i, tview.NewTableCell(header).SetSelectable(false))
+	var headerCells []*tview.TableCell
+	for _, header := range headers {
+		headerCells = append(headerCells, tview.NewTableCell(header))
 	}
+	filterTable.SetHeader(headerCells...)
 
 	go func() {
-		session.ShowMessageAsync("Loading roles", false)
+		s.ShowMessageAsync("Loading roles", false)
 
 		Roles, err := u.listRoles()
-		session.CloseMessageAsync()
+		s.CloseMessageAsync()
 
 		if err != nil {
-			session.ShowMessageAsync(fmt.Sprintf("Error: %s", err), true)
+			s.ShowMessageAsync(fmt.Sprintf("Error: %s", err), true)
 			return
 		}
 
-		session.App.QueueUpdateDraw(func() {
-			for i, Role := range Roles {
-				table.SetCell(i+1, 0, tview.NewTableCell(Role.RolName))
-				table.SetCell(i+1, 1, tview.NewTableCell(Role.Attributes))
-				table.SetCell(i+1, 2, tview.NewTableCell(Role.MemberOf))
-				table.SetCell(i+1, 3, tview.NewTableCell(Role.Description))
+		s.App.QueueUpdateDraw(func() {
+			var rows [][]*tview.TableCell
+			for _, Role := range Roles {
+				rows = append(rows, []*tview.TableCell{
+					tview.NewTableCell(Role.RolName),
+					tview.NewTableCell(Role.Attributes),
+					tview.NewTableCell(Role.MemberOf),
+					tview.NewTableCell(Role.Description),
+				})
 			}
+			filterTable.SetRows(rows)
 		})
 	}()
 
-	return table
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Rune() == 'f' {
+			filterTable.Show(s)
+			return nil
+		}
+		return event
+	})
+
+	return filterTable
 }
 
 func (u *RoleList) GetKeyBindings() (keybindings []*session.KeyBinding) {
+	keybindings = []*session.KeyBinding{
+		session.NewKeyBinding("[f]", "Filter roles"),
+	}
 	return
 }
 
@@ -0,0 +1,277 @@
+package postgresql
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const (
+	// valueMaxWidth is the maximum width of a value in the result grid. The
+	// full value is shown in the record inspector.
+	valueMaxWidth = 60
+	// binaryPreviewLength is the number of bytes shown in binary previews.
+	binaryPreviewLength = 16
+)
+
+// resultColumn describes a column of a result set.
+type resultColumn struct {
+	Name string
+	// Type is the PostgreSQL type name, e.g. "int4", "jsonb" or "text[]".
+	Type string
+}
+
+func newResultColumns(columnTypes []*sql.ColumnType) []resultColumn {
+	columns := make([]resultColumn, len(columnTypes))
+	for i, columnType := range columnTypes {
+		typeName := strings.ToLower(columnType.DatabaseTypeName())
+		if strings.HasPrefix(typeName, "_") {
+			typeName = typeName[1:] + "[]"
+		}
+		columns[i] = resultColumn{
+			Name: columnType.Name(),
+			Type: typeName,
+		}
+	}
+	return columns
+}
+
+func (c resultColumn) IsNumeric() bool {
+	switch c.Type {
+	case "int2", "int4", "int8", "float4", "float8", "numeric", "money", "oid":
+		return true
+	}
+	return false
+}
+
+func (c resultColumn) IsJSON() bool {
+	return c.Type == "json" || c.Type == "jsonb"
+}
+
+func (c resultColumn) IsArray() bool {
+	return strings.HasSuffix(c.Type, "[]")
+}
+
+// headerCell builds the header cell of a column, showing its name, a mark if
+// any, and its type.
+func (c resultColumn) headerCell(mark string) *tview.TableCell {
+	name := tview.Escape(c.Name)
+	if mark != "" {
+		name += " " + tview.Escape(mark)
+	}
+	text := fmt.Sprintf("%s [gray]%s", name, tview.Escape(c.Type))
+	cell := tview.NewTableCell(text).
+		SetSelectable(false).
+		SetAttributes(tcell.AttrBold)
+	if c.IsNumeric() {
+		cell.SetAlign(tview.AlignRight)
+	}
+	return cell
+}
+
+// lineBreakReplacer keeps the values on one line in the grid.
+var lineBreakReplacer = strings.NewReplacer("\r\n", "↵", "\n", "↵", "\r", "↵", "\t", " ")
+
+// valueCell builds the grid cell of a value. NULL is shown distinctly from an
+// empty string, and numbers are right-aligned.
+func (c resultColumn) valueCell(value any) *tview.TableCell {
+	if value == nil {
+		return tview.NewTableCell("NULL").
+			SetTextColor(tcell.ColorGray).
+			SetAttributes(tcell.AttrItalic).
+			SetReference(value)
+	}
+
+	text := lineBreakReplacer.Replace(c.formatValue(value, false))
+
+	cell := tview.NewTableCell(tview.Escape(text)).
+		SetMaxWidth(valueMaxWidth).
+		SetReference(value)
+
+	switch {
+	case c.IsNumeric():
+		cell.SetAlign(tview.AlignRight)
+	case c.IsJSON():
+		cell.SetTextColor(tcell.ColorLightSkyBlue)
+	case c.Type == "bytea":
+		cell.SetTextColor(tcell.ColorPlum)
+	}
+
+	return cell
+}
+
+// formatValue renders a value as text. The compact form fits in one line of
+// the grid, the full form is used to inspect or export the value.
+func (c resultColumn) formatValue(value any, full bool) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		switch {
+		case c.Type == "bytea":
+			return formatBinary(v, full)
+		case c.IsJSON():
+			return formatJSON(v, full)
+		case c.IsArray():
+			if elements, ok := parseArray(string(v)); ok {
+				element := resultColumn{Type: strings.TrimSuffix(c.Type, "[]")}
+				return formatJSONValue(element.typeArrayElements(elements), full)
+			}
+		}
+		return string(v)
+	case time.Time:
+		switch c.Type {
+		case "date":
+			return v.Format(time.DateOnly)
+		case "timestamp":
+			return v.Format("2006-01-02 15:04:05.999999")
+		case "time":
+			return v.Format("15:04:05.999999")
+		case "timetz":
+			return v.Format("15:04:05.999999Z07:00")
+		default:
+			return v.Format("2006-01-02 15:04:05.999999Z07:00")
+		}
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func formatBinary(data []byte, full bool) string {
+	if full || len(data) <= binaryPreviewLength {
+		return `\x` + hex.EncodeToString(data)
+	}
+	return fmt.Sprintf(`\x%s… (%d bytes)`, hex.EncodeToString(data[:binaryPreviewLength]), len(data))
+}
+
+func formatJSON(data []byte, full bool) string {
+	var buffer bytes.Buffer
+	var err error
+	if full {
+		err = json.Indent(&buffer, data, "", "  ")
+	} else {
+		err = json.Compact(&buffer, data)
+	}
+	if err != nil {
+		return string(data)
+	}
+	return buffer.String()
+}
+
+func formatJSONValue(value any, full bool) string {
+	var data []byte
+	var err error
+	if full {
+		data, err = json.MarshalIndent(value, "", "  ")
+	} else {
+		data, err = json.Marshal(value)
+	}
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// typeArrayElements converts the elements of a parsed array to JSON numbers or
+// booleans according to the element type.
+func (c resultColumn) typeArrayElements(elements []any) []any {
+	for i, element := range elements {
+		switch v := element.(type) {
+		case []any:
+			elements[i] = c.typeArrayElements(v)
+		case string:
+			switch {
+			case c.IsNumeric() && json.Valid([]byte(v)):
+				elements[i] = json.Number(v)
+			case c.Type == "bool":
+				elements[i] = v == "t"
+			}
+		}
+	}
+	return elements
+}
+
+// parseArray parses the text representation of a PostgreSQL array into nested
+// slices of strings, with nil for NULL elements.
+func parseArray(text string) ([]any, bool) {
+	// Skip the optional dimension decoration, e.g. "[1:3]={...}"
+	if strings.HasPrefix(text, "[") {
+		idx := strings.Index(text, "=")
+		if idx < 0 {
+			return nil, false
+		}
+		text = text[idx+1:]
+	}
+
+	elements, rest, ok := parseArrayElements(text)
+	if !ok || rest != "" {
+		return nil, false
+	}
+	return elements, true
+}
+
+func parseArrayElements(text string) ([]any, string, bool) {
+	if !strings.HasPrefix(text, "{") {
+		return nil, text, false
+	}
+	text = text[1:]
+
+	elements := []any{}
+	if strings.HasPrefix(text, "}") {
+		return elements, text[1:], true
+	}
+
+	for {
+		var element any
+		switch {
+		case strings.HasPrefix(text, "{"):
+			nested, rest, ok := parseArrayElements(text)
+			if !ok {
+				return nil, text, false
+			}
+			element, text = nested, rest
+		case strings.HasPrefix(text, `"`):
+			var value strings.Builder
+			i := 1
+			for ; i < len(text) && text[i] != '"'; i++ {
+				if text[i] == '\\' && i+1 < len(text) {
+					i++
+				}
+				value.WriteByte(text[i])
+			}
+			if i >= len(text) {
+				return nil, text, false
+			}
+			element, text = value.String(), text[i+1:]
+		default:
+			end := strings.IndexAny(text, ",}")
+			if end < 0 {
+				return nil, text, false
+			}
+			value := strings.TrimSpace(text[:end])
+			if strings.EqualFold(value, "NULL") {
+				element = nil
+			} else {
+				element = value
+			}
+			text = text[end:]
+		}
+		elements = append(elements, element)
+
+		switch {
+		case strings.HasPrefix(text, ","):
+			text = text[1:]
+		case strings.HasPrefix(text, "}"):
+			return elements, text[1:], true
+		default:
+			return nil, text, false
+		}
+	}
+}
@@ -43,6 +43,11 @@ func (gcp *GCPDiscovery) DiscoverInstances(form *tview.Form) (newInstances []con
 		for k, v := range instance.Tags {
 			params[k] = v
 		}
+		if instance.Settings != nil {
+			for k, v := range instance.Settings.UserLabels {
+				params[k] = v
+			}
+		}
 		newInstance := config.InstanceConfig{
 			Name:   instance.Name,
 			Source: GCP,
@@ -0,0 +1,126 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rhariady/csql/pkg/config"
+)
+
+// MaxEntries is the number of most recent entries loaded from the history.
+const MaxEntries = 5000
+
+// Entry is a query executed in the query editor.
+type Entry struct {
+	Time       time.Time `json:"time"`
+	Instance   string    `json:"instance"`
+	Database   string    `json:"database"`
+	Query      string    `json:"query"`
+	DurationMs int64     `json:"duration_ms"`
+	Success    bool      `json:"success"`
+	Error      string    `json:"error,omitempty"`
+}
+
+var mu sync.Mutex
+
+func getPath() (string, error) {
+	return config.GetDataFile("history.jsonl")
+}
+
+// Append adds an entry at the end of the history.
+func Append(entry Entry) (err error) {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	path, err := getPath()
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		c_err := file.Close()
+		if c_err != nil {
+			err = c_err
+		}
+	}()
+
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
+
+// Load returns the most recent entries of the history, newest first. Lines
+// that cannot be parsed are skipped.
+func Load() ([]Entry, error) {
+	path, err := getPath()
+	if err != nil {
+		return nil, err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+		if len(entries) > 2*MaxEntries {
+			entries = entries[len(entries)-MaxEntries:]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(entries) > MaxEntries {
+		entries = entries[len(entries)-MaxEntries:]
+	}
+
+	// Newest first
+	slices.Reverse(entries)
+
+	return entries, nil
+}
+
+// Last returns the most recent query executed on a database of an instance.
+func Last(instance string, database string) (string, bool) {
+	entries, err := Load()
+	if err != nil {
+		return "", false
+	}
+
+	for _, entry := range entries {
+		if entry.Instance == instance && entry.Database == database {
+			return entry.Query, true
+		}
+	}
+
+	return "", false
+}
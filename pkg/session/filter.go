@@ -0,0 +1,188 @@
+package session
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// FuzzyMatch reports whether every word of pattern can be found in text with
+// its characters in the same order, ignoring case. An empty pattern matches
+// everything.
+func FuzzyMatch(pattern string, text string) bool {
+	text = strings.ToLower(text)
+	for _, word := range strings.Fields(strings.ToLower(pattern)) {
+		if !fuzzyMatchWord(word, text) {
+			return false
+		}
+	}
+	return true
+}
+
+func fuzzyMatchWord(word string, text string) bool {
+	for _, r := range word {
+		idx := strings.IndexRune(text, r)
+		if idx < 0 {
+			return false
+		}
+		text = text[idx+utf8.RuneLen(r):]
+	}
+	return true
+}
+
+// FilterView wraps a primitive with an incremental filter input, shown on
+// demand above the content.
+type FilterView struct {
+	*tview.Flex
+	input   *tview.InputField
+	content tview.Primitive
+	visible bool
+	session *Session
+}
+
+func NewFilterView(content tview.Primitive, changed func(pattern string)) *FilterView {
+	input := tview.NewInputField().
+		SetLabel("Filter: ").
+		SetFieldBackgroundColor(tcell.ColorBlack).
+		SetChangedFunc(changed)
+
+	f := &FilterView{
+		Flex: tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(input, 0, 0, false).
+			AddItem(content, 0, 1, true),
+		input:   input,
+		content: content,
+	}
+
+	f.input.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEsc {
+			f.Hide()
+		}
+		if f.session != nil {
+			f.session.App.SetFocus(f.content)
+		}
+	})
+
+	return f
+}
+
+// GetPattern returns the current filter pattern.
+func (f *FilterView) GetPattern() string {
+	if !f.visible {
+		return ""
+	}
+	return f.input.GetText()
+}
+
+// Show displays the filter input and gives it the focus.
+func (f *FilterView) Show(s *Session) {
+	f.visible = true
+	f.session = s
+	f.ResizeItem(f.input, 1, 0)
+	s.App.SetFocus(f.input)
+}
+
+// Hide clears the filter and removes the filter input.
+func (f *FilterView) Hide() {
+	f.visible = false
+	f.ResizeItem(f.input, 0, 0)
+	f.input.SetText("")
+}
+
+// FilterTable is a table whose rows can be narrowed down by fuzzy matching the
+// filter pattern on any column. The header row, if any, is always shown.
+type FilterTable struct {
+	*FilterView
+	table  *tview.Table
+	header []*tview.TableCell
+	rows   [][]*tview.TableCell
+}
+
+func NewFilterTable(table *tview.Table) *FilterTable {
+	f := &FilterTable{
+		table: table,
+	}
+	f.FilterView = NewFilterView(table, func(pattern string) {
+		f.Refresh()
+	})
+
+	return f
+}
+
+// GetTable returns the underlying table.
+func (f *FilterTable) GetTable() *tview.Table {
+	return f.table
+}
+
+// SetHeader sets the header row. It is not affected by the filter.
+func (f *FilterTable) SetHeader(cells ...*tview.TableCell) *FilterTable {
+	for _, cell := range cells {
+		cell.SetSelectable(false)
+	}
+	f.header = cells
+	f.Refresh()
+	return f
+}
+
+// SetRows replaces all the data rows.
+func (f *FilterTable) SetRows(rows [][]*tview.TableCell) *FilterTable {
+	f.rows = rows
+	f.Refresh()
+	return f
+}
+
+// AddRow appends a data row.
+func (f *FilterTable) AddRow(cells ...*tview.TableCell) *FilterTable {
+	f.rows = append(f.rows, cells)
+	f.Refresh()
+	return f
+}
+
+// Refresh redraws the table with the rows matching the current filter,
+// keeping the selected row selected if it is still visible.
+func (f *FilterTable) Refresh() {
+	var selected *tview.TableCell
+	if row, _ := f.table.GetSelection(); row >= 0 && row < f.table.GetRowCount() {
+		selected = f.table.GetCell(row, 0)
+	}
+
+	f.table.Clear()
+
+	offset := 0
+	if len(f.header) > 0 {
+		for column, cell := range f.header {
+			f.table.SetCell(0, column, cell)
+		}
+		offset = 1
+	}
+
+	pattern := f.GetPattern()
+	selectedRow := offset
+	row := offset
+	for _, cells := range f.rows {
+		if !f.match(pattern, cells) {
+			continue
+		}
+		for column, cell := range cells {
+			f.table.SetCell(row, column, cell)
+		}
+		if len(cells) > 0 && cells[0] == selected {
+			selectedRow = row
+		}
+		row++
+	}
+
+	if row > offset {
+		f.table.Select(selectedRow, 0)
+	}
+}
+
+func (f *FilterTable) match(pattern string, cells []*tview.TableCell) bool {
+	texts := make([]string, len(cells))
+	for i, cell := range cells {
+		texts[i] = cell.Text
+	}
+	return FuzzyMatch(pattern, strings.Join(texts, " "))
+}
@@ -2,6 +2,8 @@ package session
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	// "github.com/gdamore/tcell/v2"
 	"github.com/gdamore/tcell/v2"
@@ -15,11 +17,32 @@ type Session struct {
 	Config *config.Config
 
 	pages      *tview.Pages
+	outerFlex  *tview.Flex
 	headerFlex *tview.Flex
+	banner     *tview.TextView
 	mainFlex   *tview.Flex
 	commandBar *tview.InputField
+	view       View
+	// modalFocus and messageFocus are restored when the modal and the message
+	// are closed
+	modalFocus   *savedFocus
+	messageFocus *savedFocus
+	message      *tview.Modal
+	// cancel aborts the operation shown in the progress message
+	cancel   func()
+	progress *tview.Modal
 }
 
+// savedFocus is a focused primitive, restored when an overlay is closed if
+// the view did not change meanwhile.
+type savedFocus struct {
+	primitive tview.Primitive
+	view      View
+}
+
+// noticeDuration is how long a notice is shown.
+const noticeDuration = 1500 * time.Millisecond
+
 type KeyBinding struct {
 	hint        string
 	description string
@@ -55,6 +78,40 @@ type View interface {
 	ExecuteCommand(*Session, string) error
 }
 
+// LeavableView is implemented by the views holding resources (e.g. open
+// cursors) that must be released when another view replaces them.
+type LeavableView interface {
+	Leave()
+}
+
+// GuardedView is implemented by the views which must warn before being
+// replaced, e.g. while they hold an open transaction.
+type GuardedView interface {
+	// GetLeaveWarning returns the warning shown before leaving the view, or
+	// an empty string when the view can be left.
+	GetLeaveWarning() string
+}
+
+// EnvironmentView is implemented by the views connected to an instance, so
+// that the environment of the instance is shown in a banner.
+type EnvironmentView interface {
+	GetEnvironment() config.Environment
+}
+
+// EnvironmentColor returns the color used to highlight an environment.
+func EnvironmentColor(environment config.Environment) tcell.Color {
+	switch environment {
+	case config.Production:
+		return tcell.ColorRed
+	case config.Staging:
+		return tcell.ColorOrange
+	case config.Development:
+		return tcell.ColorGreen
+	default:
+		return tcell.ColorGray
+	}
+}
+
 func NewSession(app *tview.Application, config *config.Config) *Session {
 	pages := tview.NewPages()
 
@@ -62,6 +119,7 @@ func NewSession(app *tview.Application, config *config.Config) *Session {
 	mainFlex.SetBorder(true)
 
 	headerFlex := tview.NewFlex()
+	banner := tview.NewTextView().SetTextAlign(tview.AlignCenter)
 	commandBar := tview.NewInputField().SetLabel("/").SetFieldBackgroundColor(tcell.ColorBlack)
 	commandBar.SetFieldWidth(0)
 
@@ -71,6 +129,7 @@ func NewSession(app *tview.Application, config *config.Config) *Session {
 
 	outerFlex := tview.NewFlex().SetDirection(tview.FlexRow).
 		AddItem(headerFlex, 6, 0, false).
+		AddItem(banner, 0, 0, false).
 		AddItem(commandFlex, 3, 0, false).
 		AddItem(mainFlex, 0, 1, true)
 
@@ -78,7 +137,9 @@ func NewSession(app *tview.Application, config *config.Config) *Session {
 		App:        app,
 		Config:     config,
 		pages:      pages,
+		outerFlex:  outerFlex,
 		headerFlex: headerFlex,
+		banner:     banner,
 		mainFlex:   mainFlex,
 		commandBar: commandBar,
 	}
@@ -92,6 +153,15 @@ func NewSession(app *tview.Application, config *config.Config) *Session {
 
 func (s *Session) setInputCapture() {
 	s.App.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		// Let text inputs receive the '/' character.
+		switch s.App.GetFocus().(type) {
+		case *tview.InputField, *tview.TextArea:
+			return event
+		}
+		if event.Key() == tcell.KeyCtrlC && s.cancel != nil {
+			s.CancelProgress()
+			return nil
+		}
 		if event.Rune() == '/' {
 			s.commandBar.SetText("")
 			s.App.SetFocus(s.commandBar)
@@ -125,12 +195,23 @@ func (s *Session) ShowHeader(info_list []Info, keyBindings []*KeyBinding) {
 		info_grid.AddItem(tview.NewTextView().SetText(info.value), i, 1, 1, 1, 0, 0, false)
 	}
 
+	// The key bindings are laid out in pairs of hint and description columns,
+	// of 6 bindings each
+	var legendColumns []int
+	for i := 0; i < len(keyBindings); i += 6 {
+		hintWidth := 8
+		for _, binding := range keyBindings[i:min(i+6, len(keyBindings))] {
+			hintWidth = max(hintWidth, len(binding.hint)+1)
+		}
+		legendColumns = append(legendColumns, hintWidth, 0)
+	}
+
 	keyLegend := tview.NewGrid().
 		SetRows(1, 1, 1, 1, 1, 1).
-		SetColumns(8, 0)
+		SetColumns(legendColumns...)
 
 	for i, binding := range keyBindings {
-		x := i / 6
+		x := i / 6 * 2
 		y := i % 6
 		keyLegend.AddItem(tview.NewTextView().SetText(binding.hint), y, x, 1, 1, 0, 0, false)
 		keyLegend.AddItem(tview.NewTextView().SetText(binding.description), y, x+1, 1, 1, 0, 0, false)
@@ -142,11 +223,61 @@ func (s *Session) ShowHeader(info_list []Info, keyBindings []*KeyBinding) {
 		AddItem(logo, 28, 0, false)
 }
 
+// ShowEnvironment shows a colored banner with the environment of the current
+// instance, or hides it when the environment is empty.
+func (s *Session) ShowEnvironment(environment config.Environment) {
+	if environment == "" {
+		s.outerFlex.ResizeItem(s.banner, 0, 0)
+		s.mainFlex.SetBorderColor(tview.Styles.BorderColor)
+		return
+	}
+
+	color := EnvironmentColor(environment)
+	s.banner.
+		SetText(fmt.Sprintf("%s ENVIRONMENT", strings.ToUpper(environment))).
+		SetTextColor(tcell.ColorWhite).
+		SetBackgroundColor(color)
+	s.outerFlex.ResizeItem(s.banner, 1, 0)
+	s.mainFlex.SetBorderColor(color)
+}
+
+// RefreshHeader redraws the header of the current view, after a change of its
+// info or key bindings.
+func (s *Session) RefreshHeader() {
+	if s.view != nil {
+		s.ShowHeader(s.view.GetInfo(), s.view.GetKeyBindings())
+	}
+}
+
+// SetView replaces the current view. When the current view is a GuardedView
+// with a warning, the user is asked to confirm first.
 func (s *Session) SetView(view View) {
+	if guardedView, ok := s.view.(GuardedView); ok && view != s.view {
+		if warning := guardedView.GetLeaveWarning(); warning != "" {
+			s.ShowAlert(warning, func(s *Session) {
+				s.setView(view)
+			}, func(s *Session) {})
+			return
+		}
+	}
+	s.setView(view)
+}
+
+func (s *Session) setView(view View) {
+	if leavableView, ok := s.view.(LeavableView); ok {
+		leavableView.Leave()
+	}
+	s.view = view
 	info := view.GetInfo()
 	keybindings := view.GetKeyBindings()
 	s.ShowHeader(info, keybindings)
 
+	var environment config.Environment
+	if environmentView, ok := view.(EnvironmentView); ok {
+		environment = environmentView.GetEnvironment()
+	}
+	s.ShowEnvironment(environment)
+
 	content := view.GetContent(s)
 	s.mainFlex.SetTitle(view.GetTitle())
 	s.mainFlex.Clear()
@@ -188,7 +319,22 @@ func (s *Session) SetView(view View) {
 }
 
 func (s *Session) ShowModal(view View) {
+	s.showModal(view, 1, 2)
+}
+
+// ShowLargeModal shows a modal covering most of the screen, for views showing
+// a lot of content.
+func (s *Session) ShowLargeModal(view View) {
+	s.showModal(view, 8, 8)
+}
+
+// showModal shows a view in a modal. width and height are the proportions of
+// the modal relatively to each of the margins around it.
+func (s *Session) showModal(view View, width int, height int) {
 	s.clearInputCapture()
+	if !s.pages.HasPage("modal") {
+		s.modalFocus = s.saveFocus()
+	}
 
 	content := view.GetContent(s)
 	keybindings := view.GetKeyBindings()
@@ -206,12 +352,12 @@ func (s *Session) ShowModal(view View) {
 
 	rowFlex := tview.NewFlex().SetDirection(tview.FlexRow).
 		AddItem(nil, 0, 1, false).
-		AddItem(modalFlex, 0, 2, true).
+		AddItem(modalFlex, 0, height, true).
 		AddItem(nil, 0, 1, false)
 
 	modal := tview.NewFlex().
 		AddItem(nil, 0, 1, false).
-		AddItem(rowFlex, 0, 1, true).
+		AddItem(rowFlex, 0, width, true).
 		AddItem(nil, 0, 1, false)
 
 	modalFlex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
@@ -229,6 +375,21 @@ func (s *Session) ShowModal(view View) {
 func (s *Session) CloseModal() {
 	s.pages.RemovePage("modal")
 	s.setInputCapture()
+	s.restoreFocus(s.modalFocus)
+	s.modalFocus = nil
+}
+
+func (s *Session) saveFocus() *savedFocus {
+	return &savedFocus{
+		primitive: s.App.GetFocus(),
+		view:      s.view,
+	}
+}
+
+func (s *Session) restoreFocus(focus *savedFocus) {
+	if focus != nil && focus.primitive != nil && focus.view == s.view {
+		s.App.SetFocus(focus.primitive)
+	}
 }
 
 func (s *Session) ShowMessageAsync(text string, wait bool) {
@@ -247,9 +408,26 @@ func (s *Session) ShowMessage(text string, wait bool) {
 			s.CloseMessage()
 		})
 	}
+	if !s.pages.HasPage("message") {
+		s.messageFocus = s.saveFocus()
+	}
+	s.message = modal
 	s.pages.AddPage("message", modal, true, true)
 }
 
+// ShowNotice shows a message that closes by itself after a short time.
+func (s *Session) ShowNotice(text string) {
+	s.ShowMessage(text, false)
+	notice := s.message
+	time.AfterFunc(noticeDuration, func() {
+		s.App.QueueUpdateDraw(func() {
+			if s.message == notice {
+				s.CloseMessage()
+			}
+		})
+	})
+}
+
 func (s *Session) CloseMessageAsync() {
 	s.App.QueueUpdateDraw(func() {
 		s.CloseMessage()
@@ -258,6 +436,59 @@ func (s *Session) CloseMessageAsync() {
 
 func (s *Session) CloseMessage() {
 	s.pages.RemovePage("message")
+	s.message = nil
+	s.restoreFocus(s.messageFocus)
+	s.messageFocus = nil
+}
+
+func (s *Session) ShowProgressAsync(text string, cancel func()) {
+	s.App.QueueUpdateDraw(func() {
+		s.ShowProgress(text, cancel)
+	})
+}
+
+// ShowProgress shows a message for a running operation, with a Cancel button.
+// Until the progress is closed, cancel is also called when Ctrl-C is pressed.
+func (s *Session) ShowProgress(text string, cancel func()) {
+	s.cancel = cancel
+
+	modal := tview.NewModal().
+		SetText(text).
+		AddButtons([]string{"Cancel"}).
+		SetDoneFunc(func(index int, label string) {
+			s.CancelProgress()
+		})
+
+	s.progress = modal
+	s.pages.AddPage("progress", modal, true, true)
+}
+
+// UpdateProgressAsync changes the message of the running operation.
+func (s *Session) UpdateProgressAsync(text string) {
+	s.App.QueueUpdateDraw(func() {
+		if s.progress != nil {
+			s.progress.SetText(text)
+		}
+	})
+}
+
+func (s *Session) CancelProgress() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.CloseProgress()
+}
+
+func (s *Session) CloseProgressAsync() {
+	s.App.QueueUpdateDraw(func() {
+		s.CloseProgress()
+	})
+}
+
+func (s *Session) CloseProgress() {
+	s.cancel = nil
+	s.progress = nil
+	s.pages.RemovePage("progress")
 }
 
 func (s *Session) ShowAlertAsync(text string, ok func(*Session), cancel func(*Session)) {
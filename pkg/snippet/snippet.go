@@ -0,0 +1,128 @@
+package snippet
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/rhariady/csql/pkg/config"
+)
+
+// Snippet is a saved query. A snippet without instance type nor instance is
+// available on every instance.
+type Snippet struct {
+	Name        string `toml:"name"`
+	Description string `toml:"description"`
+	// InstanceType restricts the snippet to a type of instance (e.g.
+	// "PostgreSQL"), ignoring case.
+	InstanceType string `toml:"instance_type"`
+	// Instance restricts the snippet to an instance, by name.
+	Instance string `toml:"instance"`
+	// Query may contain :name parameters, prompted for when it is executed.
+	Query string `toml:"query"`
+	// Path is the file the snippet was loaded from.
+	Path string `toml:"-"`
+}
+
+type snippetFile struct {
+	Snippets []Snippet `toml:"snippet"`
+}
+
+// Matches reports whether the snippet is available on an instance.
+func (s Snippet) Matches(instance *config.InstanceConfig) bool {
+	if s.Instance != "" && s.Instance != instance.Name {
+		return false
+	}
+	if s.InstanceType != "" && !strings.EqualFold(s.InstanceType, instance.Type) {
+		return false
+	}
+	return true
+}
+
+// GetScope describes the instances the snippet is available on.
+func (s Snippet) GetScope() string {
+	switch {
+	case s.Instance != "":
+		return fmt.Sprintf("instance %s", s.Instance)
+	case s.InstanceType != "":
+		return fmt.Sprintf("type %s", s.InstanceType)
+	default:
+		return "global"
+	}
+}
+
+// GetPaths returns the snippet files of the configuration.
+func GetPaths(snippetsConfig config.SnippetsConfig) ([]string, error) {
+	if len(snippetsConfig.Paths) > 0 {
+		return snippetsConfig.Paths, nil
+	}
+
+	path, err := config.GetDataFile("snippets.toml")
+	if err != nil {
+		return nil, err
+	}
+	return []string{path}, nil
+}
+
+// Load reads the snippets available on an instance from the snippet files.
+// Missing files are ignored.
+func Load(snippetsConfig config.SnippetsConfig, instance *config.InstanceConfig) ([]Snippet, error) {
+	paths, err := GetPaths(snippetsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	var snippets []Snippet
+	for _, path := range paths {
+		path = expandHome(path)
+
+		var file snippetFile
+		_, err := toml.DecodeFile(path, &file)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		for _, snippet := range file.Snippets {
+			if snippet.Matches(instance) {
+				snippet.Path = path
+				snippets = append(snippets, snippet)
+			}
+		}
+	}
+
+	return snippets, nil
+}
+
+// Find returns the snippet available on an instance with the given name.
+func Find(snippetsConfig config.SnippetsConfig, instance *config.InstanceConfig, name string) (*Snippet, error) {
+	snippets, err := Load(snippetsConfig, instance)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, snippet := range snippets {
+		if strings.EqualFold(snippet.Name, name) {
+			return &snippet, nil
+		}
+	}
+
+	return nil, fmt.Errorf("snippet %q not found", name)
+}
+
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
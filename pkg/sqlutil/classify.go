@@ -0,0 +1,251 @@
+package sqlutil
+
+import (
+	"slices"
+	"strings"
+)
+
+type StatementKind int
+
+const (
+	// Read statements do not modify anything (SELECT, SHOW, ...).
+	Read StatementKind = iota
+	// Write statements modify data (INSERT, UPDATE, DELETE, ...).
+	Write
+	// DDL statements modify the schema, permissions or the server state
+	// (CREATE, ALTER, DROP, TRUNCATE, GRANT, ...).
+	DDL
+	// Other statements control the session or the transaction (BEGIN, SET, ...).
+	Other
+)
+
+func (k StatementKind) String() string {
+	switch k {
+	case Read:
+		return "read"
+	case Write:
+		return "write"
+	case DDL:
+		return "DDL"
+	default:
+		return "other"
+	}
+}
+
+var writeKeywords = []string{"INSERT", "UPDATE", "DELETE", "MERGE", "COPY", "CALL", "DO", "EXECUTE", "LOCK"}
+
+var ddlKeywords = []string{"CREATE", "ALTER", "DROP", "TRUNCATE", "GRANT", "REVOKE", "COMMENT", "REINDEX", "VACUUM", "CLUSTER", "REFRESH", "REASSIGN", "IMPORT", "SECURITY"}
+
+var readKeywords = []string{"SELECT", "VALUES", "TABLE", "SHOW", "FETCH", "MOVE"}
+
+// Classify returns the kind of a single SQL statement, based on its leading
+// keyword. Data-modifying CTEs, SELECT INTO and EXPLAIN ANALYZE of a write
+// statement are classified by what they actually do.
+func Classify(statement string) StatementKind {
+	return classifyTokens(SignificantTokens(statement))
+}
+
+func classifyTokens(tokens []Token) StatementKind {
+	if len(tokens) == 0 {
+		return Other
+	}
+
+	first := strings.ToUpper(tokens[0].Text)
+	switch {
+	case first == "(":
+		return classifyTokens(tokens[1:])
+	case first == "WITH":
+		for _, token := range tokens[1:] {
+			for _, keyword := range []string{"INSERT", "UPDATE", "DELETE", "MERGE"} {
+				if token.IsKeyword(keyword) {
+					return Write
+				}
+			}
+		}
+		return Read
+	case first == "SELECT":
+		for i, token := range tokens {
+			// SELECT ... INTO creates a table, unlike INSERT INTO
+			if token.IsKeyword("INTO") && (i == 0 || !tokens[i-1].IsKeyword("INSERT")) {
+				return DDL
+			}
+		}
+		return Read
+	case first == "EXPLAIN":
+		analyze := false
+		rest := tokens[1:]
+		for len(rest) > 0 {
+			token := rest[0]
+			if token.IsKeyword("ANALYZE") {
+				analyze = true
+			} else if token.Text == "(" {
+				for len(rest) > 0 && rest[0].Text != ")" {
+					if rest[0].IsKeyword("ANALYZE") {
+						analyze = true
+					}
+					rest = rest[1:]
+				}
+			} else if !token.IsKeyword("VERBOSE") {
+				break
+			}
+			if len(rest) > 0 {
+				rest = rest[1:]
+			}
+		}
+		if !analyze {
+			return Read
+		}
+		return classifyTokens(rest)
+	case slices.Contains(readKeywords, first):
+		return Read
+	case slices.Contains(writeKeywords, first):
+		return Write
+	case slices.Contains(ddlKeywords, first):
+		return DDL
+	}
+
+	return Other
+}
+
+// dropObjectTypes are the types of objects of DROP statements, the longest
+// ones first.
+var dropObjectTypes = []string{
+	"TEXT SEARCH CONFIGURATION", "TEXT SEARCH DICTIONARY", "TEXT SEARCH PARSER", "TEXT SEARCH TEMPLATE",
+	"FOREIGN DATA WRAPPER",
+	"MATERIALIZED VIEW", "FOREIGN TABLE", "EVENT TRIGGER", "ACCESS METHOD", "OPERATOR CLASS", "OPERATOR FAMILY",
+	"USER MAPPING", "PROCEDURAL LANGUAGE", "OWNED BY",
+	"TABLE", "VIEW", "INDEX", "SEQUENCE", "SCHEMA", "DATABASE", "FUNCTION", "PROCEDURE", "ROUTINE", "AGGREGATE",
+	"TYPE", "DOMAIN", "EXTENSION", "TRIGGER", "RULE", "POLICY", "ROLE", "USER", "GROUP", "SERVER", "PUBLICATION",
+	"SUBSCRIPTION", "STATISTICS", "TABLESPACE", "COLLATION", "CONVERSION", "CAST", "LANGUAGE", "OPERATOR", "TRANSFORM",
+}
+
+// matchObjectType returns the object type at the start of the tokens of a
+// DROP statement, and its number of tokens, 0 when it is unknown.
+func matchObjectType(tokens []Token) (string, int) {
+	for _, objectType := range dropObjectTypes {
+		words := strings.Fields(objectType)
+		if len(tokens) < len(words) {
+			continue
+		}
+		matched := true
+		for i, word := range words {
+			token := tokens[i]
+			if (token.Type != Keyword && token.Type != Identifier) || !strings.EqualFold(token.Text, word) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return objectType, len(words)
+		}
+	}
+	return "", 0
+}
+
+// DestructiveTarget returns the command and the objects targeted by a DROP or
+// TRUNCATE statement, e.g. ("DROP TABLE", "public.users"). ok is false for any
+// other statement.
+func DestructiveTarget(statement string) (command string, objects string, ok bool) {
+	tokens := SignificantTokens(statement)
+	if len(tokens) == 0 {
+		return "", "", false
+	}
+
+	var rest []Token
+	switch {
+	case tokens[0].IsKeyword("TRUNCATE"):
+		command = "TRUNCATE"
+		rest = tokens[1:]
+		if len(rest) > 0 && rest[0].IsKeyword("TABLE") {
+			rest = rest[1:]
+		}
+	case tokens[0].IsKeyword("DROP"):
+		// The object type may be several words long (e.g. MATERIALIZED VIEW)
+		objectType, length := matchObjectType(tokens[1:])
+		if length == 0 {
+			return "", "", false
+		}
+		command = "DROP " + objectType
+		rest = tokens[1+length:]
+		if len(rest) > 1 && rest[0].IsKeyword("IF") && rest[1].IsKeyword("EXISTS") {
+			rest = rest[2:]
+		}
+	default:
+		return "", "", false
+	}
+
+	for len(rest) > 0 && (rest[0].IsKeyword("ONLY") || rest[0].IsKeyword("CONCURRENTLY")) {
+		rest = rest[1:]
+	}
+
+	var names []string
+	var name strings.Builder
+	for _, token := range rest {
+		isName := token.Type == Identifier || token.Type == QuotedIdentifier || token.Type == Keyword
+		afterDot := strings.HasSuffix(name.String(), ".")
+		switch {
+		case isName && (name.Len() == 0 || afterDot):
+			name.WriteString(token.Text)
+		case token.Text == "." && name.Len() > 0 && !afterDot:
+			name.WriteString(".")
+		case token.Text == ",":
+			names = append(names, name.String())
+			name.Reset()
+		default:
+			if name.Len() > 0 {
+				names = append(names, name.String())
+			}
+			return command, strings.Join(names, ", "), true
+		}
+	}
+	if name.Len() > 0 {
+		names = append(names, name.String())
+	}
+
+	return command, strings.Join(names, ", "), true
+}
+
+// IsCursorable reports whether a statement is a single read-only query that
+// can be used in DECLARE ... SCROLL CURSOR.
+func IsCursorable(statement string) bool {
+	tokens := SignificantTokens(statement)
+	for len(tokens) > 0 && tokens[len(tokens)-1].Text == ";" {
+		tokens = tokens[:len(tokens)-1]
+	}
+	if len(tokens) == 0 || classifyTokens(tokens) != Read {
+		return false
+	}
+
+	first := tokens[0]
+	if !first.IsKeyword("SELECT") && !first.IsKeyword("WITH") && !first.IsKeyword("VALUES") && !first.IsKeyword("TABLE") && first.Text != "(" {
+		return false
+	}
+
+	for i, token := range tokens {
+		if token.Text == ";" {
+			return false
+		}
+		// Locking clauses are not supported by scrollable cursors
+		if token.IsKeyword("FOR") && i+1 < len(tokens) {
+			next := strings.ToUpper(tokens[i+1].Text)
+			if next == "UPDATE" || next == "SHARE" || next == "NO" || next == "KEY" {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// TrimTerminator removes the trailing semicolons, comments and whitespaces of
+// a statement.
+func TrimTerminator(statement string) string {
+	tokens := SignificantTokens(statement)
+	for len(tokens) > 0 && tokens[len(tokens)-1].Text == ";" {
+		tokens = tokens[:len(tokens)-1]
+	}
+	if len(tokens) == 0 {
+		return ""
+	}
+	return statement[:tokens[len(tokens)-1].End]
+}
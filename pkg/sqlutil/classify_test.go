@@ -0,0 +1,93 @@
+package sqlutil
+
+import "testing"
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		statement string
+		want      StatementKind
+	}{
+		{"SELECT 1", Read},
+		{"  -- comment\nselect * from users", Read},
+		{"(SELECT 1) UNION (SELECT 2)", Read},
+		{"VALUES (1), (2)", Read},
+		{"SHOW search_path", Read},
+		{"SELECT * INTO backup FROM users", DDL},
+		{"INSERT INTO users SELECT * FROM old_users", Write},
+		{"WITH d AS (DELETE FROM users RETURNING *) SELECT * FROM d", Write},
+		{"WITH u AS (SELECT 1) SELECT * FROM u", Read},
+		{"UPDATE users SET name = 'x'", Write},
+		{"delete from users", Write},
+		{"EXPLAIN SELECT 1", Read},
+		{"EXPLAIN DELETE FROM users", Read},
+		{"EXPLAIN ANALYZE DELETE FROM users", Write},
+		{"EXPLAIN (ANALYZE, BUFFERS) UPDATE users SET a = 1", Write},
+		{"EXPLAIN ANALYZE VERBOSE SELECT 1", Read},
+		{"CREATE TABLE t (id int)", DDL},
+		{"DROP TABLE t", DDL},
+		{"TRUNCATE t", DDL},
+		{"REFRESH MATERIALIZED VIEW mv", DDL},
+		{"BEGIN", Other},
+		{"SET search_path TO public", Other},
+		{"", Other},
+	}
+	for _, test := range tests {
+		if got := Classify(test.statement); got != test.want {
+			t.Errorf("Classify(%q) = %s, want %s", test.statement, got, test.want)
+		}
+	}
+}
+
+func TestDestructiveTarget(t *testing.T) {
+	tests := []struct {
+		statement string
+		command   string
+		objects   string
+		ok        bool
+	}{
+		{"DROP TABLE users", "DROP TABLE", "users", true},
+		{"drop table if exists public.users, \"Orders\";", "DROP TABLE", "public.users, \"Orders\"", true},
+		{"DROP SCHEMA app CASCADE", "DROP SCHEMA", "app", true},
+		{"DROP VIEW v RESTRICT", "DROP VIEW", "v", true},
+		{"DROP TABLE a, b CASCADE", "DROP TABLE", "a, b", true},
+		{"DROP MATERIALIZED VIEW IF EXISTS reports.daily", "DROP MATERIALIZED VIEW", "reports.daily", true},
+		{"DROP INDEX CONCURRENTLY idx_users_email", "DROP INDEX", "idx_users_email", true},
+		{"DROP FOREIGN TABLE remote_users CASCADE", "DROP FOREIGN TABLE", "remote_users", true},
+		{"DROP DATABASE shop", "DROP DATABASE", "shop", true},
+		{"DROP TRIGGER audit_users ON users", "DROP TRIGGER", "audit_users", true},
+		{"DROP OWNED BY app_user CASCADE", "DROP OWNED BY", "app_user", true},
+		{"TRUNCATE users", "TRUNCATE", "users", true},
+		{"TRUNCATE TABLE ONLY users, orders RESTART IDENTITY CASCADE", "TRUNCATE", "users, orders", true},
+		{"DROP WIDGET w", "", "", false},
+		{"DELETE FROM users", "", "", false},
+		{"SELECT 1", "", "", false},
+		{"", "", "", false},
+	}
+	for _, test := range tests {
+		command, objects, ok := DestructiveTarget(test.statement)
+		if command != test.command || objects != test.objects || ok != test.ok {
+			t.Errorf("DestructiveTarget(%q) = (%q, %q, %t), want (%q, %q, %t)",
+				test.statement, command, objects, ok, test.command, test.objects, test.ok)
+		}
+	}
+}
+
+func TestIsCursorable(t *testing.T) {
+	tests := []struct {
+		statement string
+		want      bool
+	}{
+		{"SELECT * FROM users", true},
+		{"SELECT * FROM users;", true},
+		{"WITH u AS (SELECT 1) SELECT * FROM u", true},
+		{"SELECT * FROM users FOR UPDATE", false},
+		{"SELECT 1; DELETE FROM users", false},
+		{"DELETE FROM users", false},
+		{"SHOW search_path", false},
+	}
+	for _, test := range tests {
+		if got := IsCursorable(test.statement); got != test.want {
+			t.Errorf("IsCursorable(%q) = %t, want %t", test.statement, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package sqlutil
+
+import (
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// TableReference is a table referenced in a query, e.g. in a FROM clause.
+// Names are unquoted, and folded to lower case when they were not quoted.
+type TableReference struct {
+	Schema string
+	Name   string
+	Alias  string
+}
+
+// relationKeywords are the keywords followed by a table name.
+var relationKeywords = []string{"FROM", "JOIN", "UPDATE", "INTO", "TABLE"}
+
+// clauseKeywords end a table reference, they cannot be aliases.
+var clauseKeywords = []string{
+	"WHERE", "JOIN", "INNER", "LEFT", "RIGHT", "FULL", "CROSS", "NATURAL", "ON", "USING",
+	"GROUP", "ORDER", "LIMIT", "OFFSET", "HAVING", "WINDOW", "UNION", "EXCEPT", "INTERSECT",
+	"SET", "VALUES", "SELECT", "RETURNING", "FOR", "LATERAL", "WITH", "DEFAULT", "FETCH", "TABLESAMPLE",
+}
+
+func isClauseKeyword(token Token) bool {
+	return token.Type == Keyword && slices.Contains(clauseKeywords, strings.ToUpper(token.Text))
+}
+
+func isRelationKeyword(token Token) bool {
+	return token.Type == Keyword && slices.Contains(relationKeywords, strings.ToUpper(token.Text))
+}
+
+func isName(token Token) bool {
+	switch token.Type {
+	case Identifier, QuotedIdentifier:
+		return true
+	case Keyword:
+		return !isClauseKeyword(token)
+	}
+	return false
+}
+
+// TableReferences returns the tables referenced after FROM, JOIN, UPDATE,
+// INTO and TABLE in a SQL text, with their aliases.
+func TableReferences(sql string) []TableReference {
+	var references []TableReference
+
+	tokens := SignificantTokens(sql)
+	for i := 0; i < len(tokens); i++ {
+		if !isRelationKeyword(tokens[i]) {
+			continue
+		}
+		// Only a FROM clause is a list of tables
+		list := tokens[i].IsKeyword("FROM")
+
+		i++
+		for i < len(tokens) {
+			if tokens[i].IsKeyword("ONLY") {
+				i++
+			}
+			if i >= len(tokens) || !isName(tokens[i]) {
+				break
+			}
+
+			var reference TableReference
+			reference.Name = tokens[i].Identifier()
+			i++
+			if i+1 < len(tokens) && tokens[i].Text == "." && isName(tokens[i+1]) {
+				reference.Schema = reference.Name
+				reference.Name = tokens[i+1].Identifier()
+				i += 2
+			}
+
+			if i < len(tokens) && tokens[i].IsKeyword("AS") {
+				i++
+			}
+			if i < len(tokens) && isName(tokens[i]) {
+				reference.Alias = tokens[i].Identifier()
+				i++
+			}
+			references = append(references, reference)
+
+			if !list || i >= len(tokens) || tokens[i].Text != "," {
+				break
+			}
+			i++
+		}
+		// Step back on the token ending the reference, it may start another one
+		i--
+	}
+
+	return references
+}
+
+// CompletionContext describes the word being typed at a position of a SQL
+// text.
+type CompletionContext struct {
+	// Start is the byte offset of the beginning of the word.
+	Start int
+	// Prefix is the part of the word before the position.
+	Prefix string
+	// Qualifier is the name before the dot preceding the word, e.g. "u" in
+	// "u.na". It is unquoted, and folded to lower case when not quoted.
+	Qualifier string
+	// Relation is true when a table name is expected, e.g. after FROM.
+	Relation bool
+}
+
+// GetCompletionContext analyzes the word being typed at a byte offset of a
+// SQL text.
+func GetCompletionContext(sql string, offset int) CompletionContext {
+	offset = min(max(offset, 0), len(sql))
+
+	start := scanIdentifierBackward(sql, offset)
+	context := CompletionContext{
+		Start:  start,
+		Prefix: sql[start:offset],
+	}
+
+	before := start
+	if start > 0 && sql[start-1] == '.' {
+		qualifierStart := scanIdentifierBackward(sql, start-1)
+		if qualifierStart == start-1 && start > 1 && sql[start-2] == '"' {
+			// Quoted qualifier
+			if quote := strings.LastIndexByte(sql[:start-2], '"'); quote >= 0 {
+				qualifierStart = quote
+			}
+		}
+		qualifier := Tokenize(sql[qualifierStart : start-1])
+		if len(qualifier) == 1 {
+			context.Qualifier = qualifier[0].Identifier()
+		}
+		before = qualifierStart
+	}
+
+	tokens := SignificantTokens(sql[:before])
+	if len(tokens) == 0 {
+		return context
+	}
+
+	last := tokens[len(tokens)-1]
+	switch {
+	case isRelationKeyword(last):
+		context.Relation = true
+	case last.Text == ",":
+		// In a FROM list when the closest clause keyword is FROM
+		for i := len(tokens) - 2; i >= 0; i-- {
+			if tokens[i].Type != Keyword || (!isClauseKeyword(tokens[i]) && !isRelationKeyword(tokens[i])) {
+				continue
+			}
+			context.Relation = tokens[i].IsKeyword("FROM")
+			break
+		}
+	}
+
+	return context
+}
+
+// scanIdentifierBackward returns the offset of the beginning of the
+// identifier characters ending at offset.
+func scanIdentifierBackward(sql string, offset int) int {
+	start := offset
+	for start > 0 {
+		r, size := utf8.DecodeLastRuneInString(sql[:start])
+		if !isIdentifierPart(r) {
+			break
+		}
+		start -= size
+	}
+	return start
+}
@@ -0,0 +1,37 @@
+package sqlutil
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTableReferences(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want []TableReference
+	}{
+		{"SELECT 1", nil},
+		{"SELECT * FROM users", []TableReference{{Name: "users"}}},
+		{"select * from Users U", []TableReference{{Name: "users", Alias: "u"}}},
+		{
+			`SELECT * FROM public.users AS u, "App"."Orders" o`,
+			[]TableReference{{Schema: "public", Name: "users", Alias: "u"}, {Schema: "App", Name: "Orders", Alias: "o"}},
+		},
+		{
+			"SELECT * FROM users u JOIN orders o ON o.user_id = u.id LEFT JOIN items ON true WHERE u.id = 1",
+			[]TableReference{{Name: "users", Alias: "u"}, {Name: "orders", Alias: "o"}, {Name: "items"}},
+		},
+		{"SELECT * FROM ONLY parent p", []TableReference{{Name: "parent", Alias: "p"}}},
+		{"UPDATE users SET name = 'x'", []TableReference{{Name: "users"}}},
+		{"INSERT INTO log (id) VALUES (1)", []TableReference{{Name: "log"}}},
+		{"DELETE FROM users WHERE id IN (SELECT id FROM banned)", []TableReference{{Name: "users"}, {Name: "banned"}}},
+		{"TRUNCATE TABLE sessions", []TableReference{{Name: "sessions"}}},
+		{"SELECT * FROM users ORDER BY id", []TableReference{{Name: "users"}}},
+		{"SELECT * FROM ", nil},
+	}
+	for _, test := range tests {
+		if got := TableReferences(test.sql); !slices.Equal(got, test.want) {
+			t.Errorf("TableReferences(%q) = %+v, want %+v", test.sql, got, test.want)
+		}
+	}
+}
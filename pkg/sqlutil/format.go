@@ -0,0 +1,338 @@
+package sqlutil
+
+import (
+	"slices"
+	"strings"
+)
+
+// KeywordCase is how Format writes the keywords.
+type KeywordCase = string
+
+const (
+	UpperCase    KeywordCase = "upper"
+	LowerCase    KeywordCase = "lower"
+	PreserveCase KeywordCase = "preserve"
+)
+
+// FormatOptions configures Format.
+type FormatOptions struct {
+	KeywordCase KeywordCase
+	// Indent is the number of spaces of an indentation level.
+	Indent int
+}
+
+// listClauses are the clauses whose items are written one per line.
+var listClauses = []string{"SELECT", "GROUP", "ORDER", "SET", "RETURNING", "VALUES", "WINDOW"}
+
+// breakClauses are the clauses starting a new line.
+var breakClauses = []string{
+	"SELECT", "FROM", "WHERE", "GROUP", "ORDER", "HAVING", "LIMIT", "OFFSET", "FETCH",
+	"UNION", "EXCEPT", "INTERSECT", "VALUES", "SET", "RETURNING", "WINDOW",
+}
+
+// statementKeywords start a new line only at the beginning of a statement.
+var statementKeywords = []string{"WITH", "INSERT", "UPDATE", "DELETE", "MERGE"}
+
+// joinKeywords start a join, on a new line.
+var joinKeywords = []string{"JOIN", "LEFT", "RIGHT", "FULL", "INNER", "CROSS", "NATURAL"}
+
+// clauseContinuations follow a clause keyword on the same line.
+var clauseContinuations = []string{"BY", "ALL", "DISTINCT"}
+
+// functionKeywords are keywords written without space before their
+// parenthesis, like function calls.
+var functionKeywords = []string{"CAST", "ANY", "SOME", "ALL", "ARRAY", "LEFT", "RIGHT", "REPLACE", "ROW", "COALESCE"}
+
+// formatFrame is a parenthesized level of the query. Subqueries are formatted
+// like statements, other parentheses (function calls, lists) stay on a line.
+type formatFrame struct {
+	subquery bool
+	// base is the indentation level of the clauses of a subquery.
+	base int
+	// clause is the current clause keyword.
+	clause string
+	// between is true between BETWEEN and its AND.
+	between bool
+	// body is true when the items of a list clause start on the next line.
+	body bool
+	// start is true at the beginning of a statement.
+	start bool
+}
+
+type formatter struct {
+	options FormatOptions
+	out     strings.Builder
+	frames  []*formatFrame
+	// level is the indentation level of the current line.
+	level int
+	// newline is the level of the line to start before the next token, or -1.
+	newline int
+	blank   bool
+	prev    *Token
+	unary   bool
+}
+
+// Format pretty-prints SQL statements: clauses start on their own lines, the
+// items of the select lists and the conditions are written one per line, and
+// subqueries are indented. Strings and comments are kept as is.
+func Format(sql string, options FormatOptions) string {
+	if options.Indent <= 0 {
+		options.Indent = 2
+	}
+
+	f := &formatter{
+		options: options,
+		frames:  []*formatFrame{{subquery: true, start: true}},
+		newline: -1,
+	}
+
+	var tokens []Token
+	for _, token := range Tokenize(sql) {
+		if token.Type != Whitespace {
+			tokens = append(tokens, token)
+		}
+	}
+
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+
+		// A :name parameter may be merged into the preceding operator
+		if token.Type == Operator && strings.HasSuffix(token.Text, ":") && !strings.HasSuffix(token.Text, "::") &&
+			i+1 < len(tokens) && tokens[i+1].Start == token.End && (tokens[i+1].Type == Identifier || tokens[i+1].Type == Keyword) {
+			if operator := strings.TrimSuffix(token.Text, ":"); operator != "" {
+				f.format(Token{Type: Operator, Text: operator}, nil)
+			}
+			f.format(Token{Type: Identifier, Text: ":" + tokens[i+1].Text}, nil)
+			i++
+			continue
+		}
+
+		var next *Token
+		if i+1 < len(tokens) {
+			next = &tokens[i+1]
+		}
+		f.format(token, next)
+	}
+
+	return strings.TrimSpace(f.out.String())
+}
+
+func (f *formatter) frame() *formatFrame {
+	return f.frames[len(f.frames)-1]
+}
+
+func (f *formatter) breakLine(level int) {
+	f.newline = level
+}
+
+func (f *formatter) format(token Token, next *Token) {
+	frame := f.frame()
+	upper := strings.ToUpper(token.Text)
+	keyword := token.Type == Keyword && (f.prev == nil || f.prev.Text != ".")
+
+	switch {
+	case token.Type == Comment:
+		f.write(token, token.Text)
+		if strings.HasPrefix(token.Text, "--") {
+			f.breakLine(f.level)
+		}
+		return
+
+	case token.Text == ";":
+		f.write(token, token.Text)
+		f.frames = f.frames[:1]
+		*f.frames[0] = formatFrame{subquery: true, start: true}
+		f.breakLine(0)
+		f.blank = true
+		f.prev = nil
+		return
+
+	case token.Text == "(" || token.Text == "[":
+		subquery := token.Text == "(" && next != nil && (next.IsKeyword("SELECT") || next.IsKeyword("WITH") || next.IsKeyword("VALUES"))
+		f.startBody(token)
+		f.write(token, token.Text)
+		if subquery {
+			f.frames = append(f.frames, &formatFrame{subquery: true, base: f.level + 1, start: true})
+		} else {
+			f.frames = append(f.frames, &formatFrame{base: f.level})
+		}
+		return
+
+	case token.Text == ")" || token.Text == "]":
+		if len(f.frames) > 1 {
+			f.frames = f.frames[:len(f.frames)-1]
+			if frame.subquery {
+				f.breakLine(frame.base - 1)
+			}
+		}
+		f.write(token, token.Text)
+		return
+	}
+
+	if !frame.subquery {
+		f.write(token, f.keywordCase(token, keyword))
+		return
+	}
+
+	switch {
+	case keyword && upper == "ON" && next != nil && next.IsKeyword("CONFLICT"):
+		frame.clause = upper
+		frame.body = false
+		f.breakLine(frame.base)
+
+	case keyword && f.isClause(upper, next):
+		frame.clause = upper
+		frame.body = slices.Contains(listClauses, upper)
+		frame.between = false
+		f.breakLine(frame.base)
+
+	case keyword && slices.Contains(joinKeywords, upper) && !f.prevIs(joinKeywords...) && !f.prevIs("OUTER") &&
+		(upper == "JOIN" || (next != nil && (next.IsKeyword("JOIN") || next.IsKeyword("OUTER")))):
+		frame.clause = "JOIN"
+		frame.body = false
+		f.breakLine(frame.base + 1)
+
+	case keyword && upper == "ON" && frame.clause == "JOIN":
+		frame.clause = "ON"
+
+	case keyword && upper == "BETWEEN":
+		frame.between = true
+
+	case keyword && (upper == "AND" || upper == "OR"):
+		if upper == "AND" && frame.between {
+			frame.between = false
+			break
+		}
+		switch frame.clause {
+		case "WHERE", "HAVING":
+			f.breakLine(frame.base + 1)
+		case "ON":
+			f.breakLine(frame.base + 2)
+		}
+
+	case token.Text == ",":
+		f.write(token, token.Text)
+		if slices.Contains(listClauses, frame.clause) {
+			f.breakLine(frame.base + 1)
+		}
+		return
+
+	default:
+		f.startBody(token)
+	}
+
+	frame.start = false
+	f.write(token, f.keywordCase(token, keyword))
+}
+
+// isClause reports whether a keyword starts a clause of the current
+// statement.
+func (f *formatter) isClause(keyword string, next *Token) bool {
+	frame := f.frame()
+	if slices.Contains(statementKeywords, keyword) {
+		return frame.start || f.prevIs(")")
+	}
+	if !slices.Contains(breakClauses, keyword) {
+		return false
+	}
+
+	switch keyword {
+	case "FROM":
+		// DELETE FROM, IS DISTINCT FROM
+		return !f.prevIs("DELETE", "DISTINCT")
+	case "VALUES":
+		return !f.prevIs("DEFAULT")
+	case "GROUP", "ORDER":
+		return next != nil && next.IsKeyword("BY")
+	case "FETCH":
+		return next != nil && (next.IsKeyword("FIRST") || next.IsKeyword("NEXT"))
+	}
+	return true
+}
+
+// startBody starts the items of a list clause on a new line.
+func (f *formatter) startBody(token Token) {
+	frame := f.frame()
+	if !frame.body || (token.Type == Keyword && slices.Contains(clauseContinuations, strings.ToUpper(token.Text))) {
+		return
+	}
+	frame.body = false
+	f.breakLine(frame.base + 1)
+}
+
+func (f *formatter) prevIs(texts ...string) bool {
+	if f.prev == nil {
+		return false
+	}
+	for _, text := range texts {
+		if strings.EqualFold(f.prev.Text, text) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *formatter) keywordCase(token Token, keyword bool) string {
+	if !keyword {
+		return token.Text
+	}
+	switch f.options.KeywordCase {
+	case LowerCase:
+		return strings.ToLower(token.Text)
+	case PreserveCase:
+		return token.Text
+	default:
+		return strings.ToUpper(token.Text)
+	}
+}
+
+// write outputs a token, on a new line or separated from the previous token
+// by a space when needed.
+func (f *formatter) write(token Token, text string) {
+	if f.newline >= 0 && f.out.Len() > 0 {
+		if f.blank {
+			f.out.WriteString("\n")
+		}
+		f.out.WriteString("\n")
+		f.out.WriteString(strings.Repeat(" ", f.newline*f.options.Indent))
+		f.level = f.newline
+	} else if f.prev != nil && f.needsSpace(token) {
+		f.out.WriteString(" ")
+	}
+	f.newline = -1
+	f.blank = false
+
+	// A sign is unary after an operator, a separator or a keyword
+	f.unary = token.Type == Operator && (text == "-" || text == "+") &&
+		(f.prev == nil || f.prev.Type == Operator || f.prev.Type == Keyword || f.prev.Text == "(" || f.prev.Text == ",")
+
+	f.out.WriteString(text)
+	f.prev = &token
+}
+
+func (f *formatter) needsSpace(token Token) bool {
+	prev := f.prev
+	switch {
+	case f.unary:
+		return false
+	case token.Text == "," || token.Text == ";" || token.Text == ")" || token.Text == "]" || token.Text == ".":
+		return false
+	case prev.Text == "(" || prev.Text == "[" || prev.Text == "." || prev.Text == "::":
+		return false
+	case token.Text == "::":
+		return false
+	case token.Text == "[":
+		return prev.Type != Identifier && prev.Type != QuotedIdentifier && prev.Text != ")" && prev.Text != "]" &&
+			!(prev.Type == Keyword && strings.EqualFold(prev.Text, "ARRAY"))
+	case token.Text == "(":
+		switch prev.Type {
+		case Identifier, QuotedIdentifier:
+			// Function calls, unless the name was followed by a space, e.g.
+			// the columns of INSERT INTO t (a, b)
+			return token.Start > prev.End
+		case Keyword:
+			return !slices.Contains(functionKeywords, strings.ToUpper(prev.Text))
+		}
+	}
+	return true
+}
@@ -0,0 +1,100 @@
+package sqlutil
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{
+			"select id, name from users where id = 1 and name like 'a%' order by name",
+			"SELECT\n  id,\n  name\nFROM users\nWHERE id = 1\n  AND name LIKE 'a%'\nORDER BY\n  name",
+		},
+		{
+			"select * from users u join orders o on o.user_id = u.id",
+			"SELECT\n  *\nFROM users u\n  JOIN orders o ON o.user_id = u.id",
+		},
+		{
+			"select 1; select 2",
+			"SELECT\n  1;\n\nSELECT\n  2",
+		},
+		{
+			"select x::int, -1, a[1:2] from t where b between 1 and 2",
+			"SELECT\n  x::int,\n  -1,\n  a[1 : 2]\nFROM t\nWHERE b BETWEEN 1 AND 2",
+		},
+		{
+			"select * from users where id = :id",
+			"SELECT\n  *\nFROM users\nWHERE id = :id",
+		},
+		{
+			"update users set name = 'x', age = 2 where id = 1 returning id",
+			"UPDATE users\nSET\n  name = 'x',\n  age = 2\nWHERE id = 1\nRETURNING\n  id",
+		},
+	}
+	for _, test := range tests {
+		if got := Format(test.sql, FormatOptions{}); got != test.want {
+			t.Errorf("Format(%q) =\n%s\nwant\n%s", test.sql, got, test.want)
+		}
+	}
+}
+
+func TestFormatKeywordCase(t *testing.T) {
+	tests := []struct {
+		keywordCase KeywordCase
+		want        string
+	}{
+		{UpperCase, "SELECT\n  Name\nFROM users"},
+		{LowerCase, "select\n  Name\nfrom users"},
+		{PreserveCase, "Select\n  Name\nfrom users"},
+	}
+	for _, test := range tests {
+		got := Format("Select Name from users", FormatOptions{KeywordCase: test.keywordCase})
+		if got != test.want {
+			t.Errorf("Format with %s keywords =\n%s\nwant\n%s", test.keywordCase, got, test.want)
+		}
+	}
+}
+
+// TestFormatRoundTrip checks that formatting keeps the tokens of the
+// statements, apart from the case of the keywords, and that formatting a
+// formatted text does not change it.
+func TestFormatRoundTrip(t *testing.T) {
+	tests := []string{
+		"select id, name from users where id = 1 and (name = 'x' or name is null)",
+		"WITH recent AS (SELECT * FROM orders WHERE created_at > now() - interval '1 day') SELECT count(*) FROM recent",
+		"select * from (select id from users) u left join orders o on o.user_id = u.id group by u.id having count(*) > 1",
+		"insert into users (id, name) values (1, 'a'), (2, 'b') on conflict (id) do update set name = excluded.name",
+		"select 'it''s; here', \"Mixed Case\", $$ body ; $$, E'\\n' -- trailing; comment\nfrom t /* block; comment */",
+		"select cast(x as int), array[1, 2], coalesce(a, b) from t order by 1 desc limit 10 offset 5",
+		"delete from users where id in (select id from old_users) returning *; truncate t;",
+	}
+	for _, sql := range tests {
+		formatted := Format(sql, FormatOptions{})
+		if got, want := tokenTexts(formatted), tokenTexts(sql); got != want {
+			t.Errorf("Format(%q) changed the tokens:\n%s\nwant\n%s", sql, got, want)
+		}
+		if again := Format(formatted, FormatOptions{}); again != formatted {
+			t.Errorf("Format is not stable for %q:\n%s\nthen\n%s", sql, formatted, again)
+		}
+	}
+}
+
+// tokenTexts returns the texts of the tokens of a SQL text, except the
+// whitespaces, with the keywords in upper case.
+func tokenTexts(sql string) string {
+	var texts []string
+	for _, token := range Tokenize(sql) {
+		switch token.Type {
+		case Whitespace:
+		case Keyword:
+			texts = append(texts, strings.ToUpper(token.Text))
+		default:
+			texts = append(texts, token.Text)
+		}
+	}
+	return strings.Join(texts, " ")
+}
@@ -0,0 +1,44 @@
+package sqlutil
+
+import (
+	"strings"
+)
+
+// Keywords is the list of SQL keywords recognized by the lexer.
+var Keywords = []string{
+	"ABORT", "ALL", "ALTER", "ANALYZE", "AND", "ANY", "ARRAY", "AS", "ASC",
+	"BEGIN", "BETWEEN", "BIGINT", "BOOLEAN", "BOTH", "BY",
+	"CALL", "CASCADE", "CASE", "CAST", "CHECK", "CHECKPOINT", "CLOSE", "CLUSTER", "COLLATE", "COLUMN", "COMMENT", "COMMIT", "CONCURRENTLY", "CONFLICT", "CONSTRAINT", "COPY", "CREATE", "CROSS", "CURRENT_DATE", "CURRENT_TIMESTAMP", "CURRENT_USER", "CURSOR",
+	"DATABASE", "DEALLOCATE", "DECLARE", "DEFAULT", "DELETE", "DESC", "DISCARD", "DISTINCT", "DO", "DOMAIN", "DROP",
+	"ELSE", "END", "ESCAPE", "EXCEPT", "EXCLUDE", "EXECUTE", "EXISTS", "EXPLAIN", "EXTENSION",
+	"FALSE", "FETCH", "FILTER", "FIRST", "FOR", "FOREIGN", "FROM", "FULL", "FUNCTION",
+	"GRANT", "GROUP",
+	"HAVING",
+	"IF", "ILIKE", "IMPORT", "IN", "INDEX", "INNER", "INSERT", "INTERSECT", "INTERVAL", "INTO", "IS", "ISNULL",
+	"JOIN",
+	"KEY",
+	"LAST", "LATERAL", "LEADING", "LEFT", "LIKE", "LIMIT", "LISTEN", "LOCK",
+	"MATERIALIZED", "MERGE", "MOVE",
+	"NATURAL", "NOT", "NOTIFY", "NOTNULL", "NULL", "NULLS",
+	"OFFSET", "ON", "ONLY", "OR", "ORDER", "OUTER", "OVER", "OVERLAPS",
+	"PARTITION", "POLICY", "PREPARE", "PRIMARY", "PROCEDURE",
+	"REASSIGN", "RECURSIVE", "REFERENCES", "REFRESH", "REINDEX", "RELEASE", "RENAME", "REPLACE", "RESET", "RESTRICT", "RETURNING", "REVOKE", "RIGHT", "ROLE", "ROLLBACK", "ROW", "ROWS",
+	"SAVEPOINT", "SCHEMA", "SECURITY", "SELECT", "SEQUENCE", "SESSION_USER", "SET", "SHOW", "SIMILAR", "SOME", "START",
+	"TABLE", "TABLESAMPLE", "TEMP", "TEMPORARY", "THEN", "TO", "TRAILING", "TRANSACTION", "TRIGGER", "TRUE", "TRUNCATE", "TYPE",
+	"UNION", "UNIQUE", "UNLISTEN", "UPDATE", "USER", "USING",
+	"VACUUM", "VALUES", "VARIADIC", "VERBOSE", "VIEW",
+	"WHEN", "WHERE", "WINDOW", "WITH", "WITHIN", "WITHOUT",
+}
+
+var keywordSet map[string]bool
+
+// IsKeyword reports whether word is a SQL keyword, ignoring case.
+func IsKeyword(word string) bool {
+	if keywordSet == nil {
+		keywordSet = make(map[string]bool)
+		for _, keyword := range Keywords {
+			keywordSet[keyword] = true
+		}
+	}
+	return keywordSet[strings.ToUpper(word)]
+}
@@ -0,0 +1,242 @@
+package sqlutil
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type TokenType int
+
+const (
+	Whitespace TokenType = iota
+	Comment
+	Keyword
+	Identifier
+	QuotedIdentifier
+	String
+	Number
+	Operator
+	Punctuation
+)
+
+// Token is a lexical element of a SQL text. Start and End are byte offsets in
+// the tokenized text.
+type Token struct {
+	Type  TokenType
+	Text  string
+	Start int
+	End   int
+}
+
+// IsKeyword reports whether the token is the given keyword, ignoring case.
+func (t Token) IsKeyword(keyword string) bool {
+	return t.Type == Keyword && strings.EqualFold(t.Text, keyword)
+}
+
+// IsSignificant reports whether the token is neither a whitespace nor a comment.
+func (t Token) IsSignificant() bool {
+	return t.Type != Whitespace && t.Type != Comment
+}
+
+// Tokenize splits a SQL text into tokens, following the PostgreSQL lexical
+// rules for comments, strings, dollar-quoted strings and quoted identifiers.
+// Unterminated strings and comments extend to the end of the text.
+func Tokenize(sql string) []Token {
+	var tokens []Token
+
+	pos := 0
+	for pos < len(sql) {
+		tokenType, end := scanToken(sql, pos)
+		text := sql[pos:end]
+		if tokenType == Identifier && IsKeyword(text) {
+			tokenType = Keyword
+		}
+		tokens = append(tokens, Token{
+			Type:  tokenType,
+			Text:  text,
+			Start: pos,
+			End:   end,
+		})
+		pos = end
+	}
+
+	return tokens
+}
+
+// SignificantTokens returns the tokens of a SQL text, without whitespaces and
+// comments.
+func SignificantTokens(sql string) []Token {
+	var tokens []Token
+	for _, token := range Tokenize(sql) {
+		if token.IsSignificant() {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
+
+func scanToken(sql string, pos int) (TokenType, int) {
+	r, size := utf8.DecodeRuneInString(sql[pos:])
+	rest := sql[pos:]
+
+	switch {
+	case unicode.IsSpace(r):
+		end := pos + size
+		for end < len(sql) {
+			r, size := utf8.DecodeRuneInString(sql[end:])
+			if !unicode.IsSpace(r) {
+				break
+			}
+			end += size
+		}
+		return Whitespace, end
+	case strings.HasPrefix(rest, "--"):
+		end := strings.IndexByte(rest, '\n')
+		if end < 0 {
+			return Comment, len(sql)
+		}
+		return Comment, pos + end
+	case strings.HasPrefix(rest, "/*"):
+		return Comment, scanBlockComment(sql, pos)
+	case r == '\'':
+		return String, scanQuoted(sql, pos+1, '\'', false)
+	case (r == 'E' || r == 'e') && strings.HasPrefix(rest[1:], "'"):
+		return String, scanQuoted(sql, pos+2, '\'', true)
+	case r == '"':
+		return QuotedIdentifier, scanQuoted(sql, pos+1, '"', false)
+	case r == '$':
+		if tag, ok := dollarTag(rest); ok {
+			end := strings.Index(rest[len(tag):], tag)
+			if end < 0 {
+				return String, len(sql)
+			}
+			return String, pos + len(tag) + end + len(tag)
+		}
+		// Positional parameter ($1)
+		end := pos + 1
+		for end < len(sql) && isDigit(sql[end]) {
+			end++
+		}
+		return Identifier, end
+	case (r >= '0' && r <= '9') || (r == '.' && len(rest) > 1 && isDigit(rest[1])):
+		return Number, scanNumber(sql, pos)
+	case isIdentifierStart(r):
+		end := pos + size
+		for end < len(sql) {
+			r, size := utf8.DecodeRuneInString(sql[end:])
+			if !isIdentifierPart(r) {
+				break
+			}
+			end += size
+		}
+		return Identifier, end
+	case strings.ContainsRune("(),;[].", r):
+		return Punctuation, pos + size
+	case strings.ContainsRune("+-*/<>=~!@#%^&|`?:", r):
+		end := pos + size
+		for end < len(sql) && strings.ContainsRune("+-*/<>=~!@#%^&|`?:", rune(sql[end])) {
+			if strings.HasPrefix(sql[end:], "--") || strings.HasPrefix(sql[end:], "/*") {
+				break
+			}
+			end++
+		}
+		return Operator, end
+	}
+
+	return Operator, pos + size
+}
+
+func scanBlockComment(sql string, pos int) int {
+	depth := 0
+	for pos < len(sql) {
+		switch {
+		case strings.HasPrefix(sql[pos:], "/*"):
+			depth++
+			pos += 2
+		case strings.HasPrefix(sql[pos:], "*/"):
+			depth--
+			pos += 2
+			if depth == 0 {
+				return pos
+			}
+		default:
+			pos++
+		}
+	}
+	return len(sql)
+}
+
+func scanQuoted(sql string, pos int, quote byte, backslashEscapes bool) int {
+	for pos < len(sql) {
+		switch {
+		case backslashEscapes && sql[pos] == '\\':
+			pos += 2
+		case sql[pos] == quote:
+			if pos+1 < len(sql) && sql[pos+1] == quote {
+				pos += 2
+				continue
+			}
+			return pos + 1
+		default:
+			pos++
+		}
+	}
+	return len(sql)
+}
+
+func scanNumber(sql string, pos int) int {
+	end := pos
+	for end < len(sql) && (isDigit(sql[end]) || sql[end] == '.' || sql[end] == '_') {
+		end++
+	}
+	if end < len(sql) && (sql[end] == 'e' || sql[end] == 'E') {
+		exp := end + 1
+		if exp < len(sql) && (sql[exp] == '+' || sql[exp] == '-') {
+			exp++
+		}
+		if exp < len(sql) && isDigit(sql[exp]) {
+			end = exp
+			for end < len(sql) && isDigit(sql[end]) {
+				end++
+			}
+		}
+	}
+	return end
+}
+
+// dollarTag returns the opening tag of a dollar-quoted string ($$ or $tag$).
+func dollarTag(text string) (string, bool) {
+	for i, r := range text[1:] {
+		if r == '$' {
+			return text[:i+2], true
+		}
+		if !isIdentifierPart(r) || (i == 0 && unicode.IsDigit(r)) {
+			return "", false
+		}
+	}
+	return "", false
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+func isIdentifierStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isIdentifierPart(r rune) bool {
+	return r == '_' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// Identifier returns the name designated by an identifier token, unquoting
+// quoted identifiers and folding unquoted ones to lower case.
+func (t Token) Identifier() string {
+	if t.Type == QuotedIdentifier {
+		name := strings.TrimPrefix(t.Text, `"`)
+		name = strings.TrimSuffix(name, `"`)
+		return strings.ReplaceAll(name, `""`, `"`)
+	}
+	return strings.ToLower(t.Text)
+}
@@ -0,0 +1,71 @@
+package sqlutil
+
+import (
+	"slices"
+	"strings"
+)
+
+// namedParameter is the position of a :name parameter in a SQL text.
+type namedParameter struct {
+	name  string
+	start int
+	end   int
+}
+
+func findNamedParameters(sql string) []namedParameter {
+	var params []namedParameter
+
+	tokens := Tokenize(sql)
+	for i, token := range tokens {
+		if token.Type != Operator || !strings.HasSuffix(token.Text, ":") || strings.HasSuffix(token.Text, "::") {
+			continue
+		}
+		if i+1 >= len(tokens) {
+			continue
+		}
+		next := tokens[i+1]
+		if next.Type != Identifier && next.Type != Keyword {
+			continue
+		}
+		// A colon right after a value is an array slice (a[1:n])
+		if token.Text == ":" && i > 0 {
+			previous := tokens[i-1]
+			if previous.Type == Identifier || previous.Type == Number || previous.Type == QuotedIdentifier || previous.Text == "]" || previous.Text == ")" {
+				continue
+			}
+		}
+		params = append(params, namedParameter{
+			name:  next.Text,
+			start: token.End - 1,
+			end:   next.End,
+		})
+	}
+
+	return params
+}
+
+// NamedParameters returns the names of the :name parameters of a SQL text, in
+// the order of their first appearance.
+func NamedParameters(sql string) []string {
+	var names []string
+	for _, param := range findNamedParameters(sql) {
+		if !slices.Contains(names, param.name) {
+			names = append(names, param.name)
+		}
+	}
+	return names
+}
+
+// BindNamedParameters replaces the :name parameters of a SQL text with the
+// text returned by value for their name.
+func BindNamedParameters(sql string, value func(name string) string) string {
+	var bound strings.Builder
+	pos := 0
+	for _, param := range findNamedParameters(sql) {
+		bound.WriteString(sql[pos:param.start])
+		bound.WriteString(value(param.name))
+		pos = param.end
+	}
+	bound.WriteString(sql[pos:])
+	return bound.String()
+}
@@ -0,0 +1,59 @@
+package sqlutil
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNamedParameters(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want []string
+	}{
+		{"SELECT 1", nil},
+		{"SELECT * FROM t WHERE a = :a AND b = :b OR a = :a", []string{"a", "b"}},
+		{"SELECT * FROM t WHERE a=:a", []string{"a"}},
+		{"SELECT x::int, ':quoted', \":ident\" -- :comment", nil},
+		{"SELECT a[1:n], a[:n], f(:first)", []string{"n", "first"}},
+	}
+	for _, test := range tests {
+		if got := NamedParameters(test.sql); !slices.Equal(got, test.want) {
+			t.Errorf("NamedParameters(%q) = %q, want %q", test.sql, got, test.want)
+		}
+	}
+}
+
+func TestBindNamedParameters(t *testing.T) {
+	values := map[string]string{"id": "42", "name": "'o''brien'"}
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"SELECT 1", "SELECT 1"},
+		{"SELECT * FROM t WHERE id = :id", "SELECT * FROM t WHERE id = 42"},
+		{"SELECT * FROM t WHERE id=:id AND name = :name", "SELECT * FROM t WHERE id=42 AND name = 'o''brien'"},
+		{"SELECT :id, :id::text", "SELECT 42, 42::text"},
+		{"SELECT ':id', x::id, a[1:id] FROM t -- :id", "SELECT ':id', x::id, a[1:id] FROM t -- :id"},
+		{"SELECT $$ :id $$, :name", "SELECT $$ :id $$, 'o''brien'"},
+	}
+	for _, test := range tests {
+		got := BindNamedParameters(test.sql, func(name string) string {
+			return values[name]
+		})
+		if got != test.want {
+			t.Errorf("BindNamedParameters(%q) = %q, want %q", test.sql, got, test.want)
+		}
+	}
+}
+
+func TestBindNamedParametersNames(t *testing.T) {
+	var names []string
+	BindNamedParameters("SELECT :b, :a, :b", func(name string) string {
+		names = append(names, name)
+		return strings.ToUpper(name)
+	})
+	if want := []string{"b", "a", "b"}; !slices.Equal(names, want) {
+		t.Errorf("BindNamedParameters asked for %q, want %q", names, want)
+	}
+}
@@ -0,0 +1,109 @@
+package sqlutil
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Statement is a statement of a SQL script. Start and End are the byte offsets
+// of the statement in the script, End including its terminating semicolon.
+type Statement struct {
+	Text  string
+	Start int
+	End   int
+}
+
+// SplitStatements splits a SQL script on its semicolons, ignoring those in
+// strings, dollar-quoted strings, quoted identifiers and comments. Empty
+// statements are dropped, and the leading whitespaces and comments are not
+// part of the statements.
+func SplitStatements(sql string) []Statement {
+	var statements []Statement
+
+	start := -1
+	for _, token := range Tokenize(sql) {
+		if !token.IsSignificant() {
+			continue
+		}
+		if token.Text == ";" {
+			if start >= 0 {
+				statements = append(statements, Statement{Text: sql[start:token.End], Start: start, End: token.End})
+			}
+			start = -1
+			continue
+		}
+		if start < 0 {
+			start = token.Start
+		}
+	}
+	if start >= 0 {
+		end := len(strings.TrimRightFunc(sql, unicode.IsSpace))
+		statements = append(statements, Statement{Text: sql[start:end], Start: start, End: end})
+	}
+
+	return statements
+}
+
+// StatementAt returns the statement of a script at a byte offset: the
+// statement containing the offset, or else the last one before it.
+func StatementAt(statements []Statement, offset int) (Statement, bool) {
+	if len(statements) == 0 {
+		return Statement{}, false
+	}
+
+	found := statements[0]
+	for _, statement := range statements {
+		if statement.Start > offset {
+			break
+		}
+		found = statement
+	}
+	return found, true
+}
+
+// objectModifiers are the keywords between CREATE, ALTER or DROP and the type
+// of object, which are not part of the command tags.
+var objectModifiers = []string{"OR", "REPLACE", "UNIQUE", "TEMP", "TEMPORARY", "UNLOGGED", "GLOBAL", "LOCAL", "TRUSTED", "PROCEDURAL", "RECURSIVE", "CONCURRENTLY"}
+
+// CommandTag returns the tag reported by PostgreSQL for a statement which
+// returned no rows, like "UPDATE 42" or "CREATE TABLE". rowsAffected is
+// ignored when negative.
+func CommandTag(statement string, rowsAffected int64) string {
+	tokens := SignificantTokens(statement)
+	if len(tokens) == 0 {
+		return ""
+	}
+
+	command := strings.ToUpper(tokens[0].Text)
+	switch command {
+	case "INSERT":
+		if rowsAffected >= 0 {
+			return fmt.Sprintf("INSERT 0 %d", rowsAffected)
+		}
+	case "UPDATE", "DELETE", "MERGE", "COPY", "MOVE", "FETCH", "SELECT":
+		if rowsAffected >= 0 {
+			return fmt.Sprintf("%s %d", command, rowsAffected)
+		}
+	case "TRUNCATE":
+		return "TRUNCATE TABLE"
+	case "CREATE", "ALTER", "DROP":
+		for i := 1; i < len(tokens); i++ {
+			word := strings.ToUpper(tokens[i].Text)
+			if tokens[i].Type != Keyword && tokens[i].Type != Identifier {
+				break
+			}
+			if slices.Contains(objectModifiers, word) {
+				continue
+			}
+			// MATERIALIZED VIEW, FOREIGN TABLE, ...
+			if (word == "MATERIALIZED" || word == "FOREIGN" || word == "EVENT" || word == "TEXT") && i+1 < len(tokens) {
+				return fmt.Sprintf("%s %s %s", command, word, strings.ToUpper(tokens[i+1].Text))
+			}
+			return fmt.Sprintf("%s %s", command, word)
+		}
+	}
+
+	return command
+}
@@ -0,0 +1,93 @@
+package sqlutil
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want []string
+	}{
+		{"", nil},
+		{" ; ;\n", nil},
+		{"SELECT 1", []string{"SELECT 1"}},
+		{"SELECT 1;SELECT 2;", []string{"SELECT 1;", "SELECT 2;"}},
+		{"SELECT 1;\n  SELECT 2  \n", []string{"SELECT 1;", "SELECT 2"}},
+		{"SELECT 'a;b'; SELECT 2", []string{"SELECT 'a;b';", "SELECT 2"}},
+		{"SELECT 'it''s;'; SELECT E'\\';'", []string{"SELECT 'it''s;';", "SELECT E'\\';'"}},
+		{`SELECT "a;b" FROM t; SELECT 2`, []string{`SELECT "a;b" FROM t;`, "SELECT 2"}},
+		{
+			"CREATE FUNCTION f() RETURNS int AS $$ SELECT 1; $$ LANGUAGE sql; SELECT f()",
+			[]string{"CREATE FUNCTION f() RETURNS int AS $$ SELECT 1; $$ LANGUAGE sql;", "SELECT f()"},
+		},
+		{
+			"DO $body$ BEGIN PERFORM 1; $x$ ; $x$; END $body$; SELECT 2",
+			[]string{"DO $body$ BEGIN PERFORM 1; $x$ ; $x$; END $body$;", "SELECT 2"},
+		},
+		{"-- first; comment\nSELECT 1; -- second;\nSELECT 2", []string{"SELECT 1;", "SELECT 2"}},
+		{"/* a; /* nested; */ b; */ SELECT 1; SELECT 2", []string{"SELECT 1;", "SELECT 2"}},
+		{"SELECT 1 /* ; */ + 2", []string{"SELECT 1 /* ; */ + 2"}},
+	}
+	for _, test := range tests {
+		var got []string
+		for _, statement := range SplitStatements(test.sql) {
+			if test.sql[statement.Start:statement.End] != statement.Text {
+				t.Errorf("SplitStatements(%q): %q is not at [%d:%d]", test.sql, statement.Text, statement.Start, statement.End)
+			}
+			got = append(got, statement.Text)
+		}
+		if !slices.Equal(got, test.want) {
+			t.Errorf("SplitStatements(%q) = %q, want %q", test.sql, got, test.want)
+		}
+	}
+}
+
+func TestStatementAt(t *testing.T) {
+	sql := "SELECT 1;\n\nSELECT 2; SELECT 3"
+	statements := SplitStatements(sql)
+	tests := []struct {
+		offset int
+		want   string
+	}{
+		{0, "SELECT 1;"},
+		{8, "SELECT 1;"},
+		{10, "SELECT 1;"},
+		{11, "SELECT 2;"},
+		{len(sql), "SELECT 3"},
+	}
+	for _, test := range tests {
+		statement, ok := StatementAt(statements, test.offset)
+		if !ok || statement.Text != test.want {
+			t.Errorf("StatementAt(%d) = %q, %t, want %q", test.offset, statement.Text, ok, test.want)
+		}
+	}
+
+	if _, ok := StatementAt(nil, 0); ok {
+		t.Errorf("StatementAt without statements is ok")
+	}
+}
+
+func TestCommandTag(t *testing.T) {
+	tests := []struct {
+		statement    string
+		rowsAffected int64
+		want         string
+	}{
+		{"INSERT INTO t VALUES (1)", 1, "INSERT 0 1"},
+		{"update t set a = 1", 42, "UPDATE 42"},
+		{"DELETE FROM t", -1, "DELETE"},
+		{"TRUNCATE t", 0, "TRUNCATE TABLE"},
+		{"CREATE OR REPLACE VIEW v AS SELECT 1", 0, "CREATE VIEW"},
+		{"create unique index i on t (a)", 0, "CREATE INDEX"},
+		{"DROP MATERIALIZED VIEW mv", 0, "DROP MATERIALIZED VIEW"},
+		{"-- comment\nSET search_path TO public", 0, "SET"},
+		{"", 0, ""},
+	}
+	for _, test := range tests {
+		if got := CommandTag(test.statement, test.rowsAffected); got != test.want {
+			t.Errorf("CommandTag(%q, %d) = %q, want %q", test.statement, test.rowsAffected, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package workspace
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/rhariady/csql/pkg/config"
+)
+
+// Buffer is a named query buffer of the query editor.
+type Buffer struct {
+	Name string `json:"name"`
+	Text string `json:"text"`
+}
+
+// Workspace is the query buffers of the query editor for a database of an
+// instance.
+type Workspace struct {
+	Instance string   `json:"instance"`
+	Database string   `json:"database"`
+	Buffers  []Buffer `json:"buffers"`
+	// Current is the index of the buffer shown in the editor.
+	Current int `json:"current"`
+}
+
+var mu sync.Mutex
+
+func getPath() (string, error) {
+	return config.GetDataFile("workspaces.json")
+}
+
+// setAsideError is the warning returned when the saved workspaces could not
+// be parsed: the file is renamed, and the workspaces start empty.
+type setAsideError struct {
+	path   string
+	backup string
+	err    error
+}
+
+func (e *setAsideError) Error() string {
+	return fmt.Sprintf("%s could not be read and was renamed to %s, the buffers start empty: %s", e.path, e.backup, e.err)
+}
+
+func (e *setAsideError) Unwrap() error {
+	return e.err
+}
+
+// loadAll reads the saved workspaces. A file which cannot be parsed, e.g.
+// after a crash while it was written, is set aside with a *setAsideError, so
+// that the workspaces can be saved again.
+func loadAll(path string) ([]Workspace, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var workspaces []Workspace
+	if err := json.Unmarshal(data, &workspaces); err != nil {
+		backup := path + ".corrupted"
+		if r_err := os.Rename(path, backup); r_err != nil {
+			return nil, err
+		}
+		return nil, &setAsideError{path: path, backup: backup, err: err}
+	}
+	return workspaces, nil
+}
+
+// writeAll replaces the saved workspaces. The file is written to a temporary
+// file renamed into place, so that it is never left half written.
+func writeAll(path string, workspaces []Workspace) error {
+	data, err := json.MarshalIndent(workspaces, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), "workspaces-*.json")
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	return nil
+}
+
+// Load returns the saved workspace of a database of an instance. ok is false
+// when none was saved. err is a *setAsideError when the saved workspaces could
+// not be parsed and were set aside.
+func Load(instance string, database string) (workspace Workspace, ok bool, err error) {
+	path, err := getPath()
+	if err != nil {
+		return Workspace{}, false, err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	workspaces, err := loadAll(path)
+	if err != nil {
+		return Workspace{}, false, err
+	}
+
+	for _, workspace := range workspaces {
+		if workspace.Instance == instance && workspace.Database == database {
+			return workspace, true, nil
+		}
+	}
+	return Workspace{}, false, nil
+}
+
+// Save replaces the saved workspace of the database of the workspace. A
+// workspace without buffers is removed. The workspace is saved even when the
+// saved workspaces were set aside, the *setAsideError being returned as a
+// warning.
+func Save(workspace Workspace) error {
+	path, err := getPath()
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	workspaces, loadErr := loadAll(path)
+	var setAside *setAsideError
+	if loadErr != nil && !errors.As(loadErr, &setAside) {
+		return loadErr
+	}
+
+	workspaces = slices.DeleteFunc(workspaces, func(saved Workspace) bool {
+		return saved.Instance == workspace.Instance && saved.Database == workspace.Database
+	})
+	if len(workspace.Buffers) > 0 {
+		workspaces = append(workspaces, workspace)
+	}
+
+	if err := writeAll(path, workspaces); err != nil {
+		return err
+	}
+	return loadErr
+}